@@ -20,6 +20,16 @@ func AssertDocumentsEqual(t *testing.T, got, want Document) {
 		t.Errorf("want Version %q, got %q", want.Version, got.Version)
 	}
 
+	if len(got.Namespaces) != len(want.Namespaces) {
+		t.Errorf("want %d Namespaces, got %d", len(want.Namespaces), len(got.Namespaces))
+	} else {
+		for index, wantNamespace := range want.Namespaces {
+			if got.Namespaces[index] != wantNamespace {
+				t.Errorf("want Namespace %d %v, got %v", index, wantNamespace, got.Namespaces[index])
+			}
+		}
+	}
+
 	// Head
 	if !got.Head.DateCreated.Equal(want.Head.DateCreated) {
 		t.Errorf("want Head > DateCreated %q, got %q", want.Head.DateCreated, got.Head.DateCreated)
@@ -33,6 +43,12 @@ func AssertDocumentsEqual(t *testing.T, got, want Document) {
 	if got.Head.OwnerEmail != want.Head.OwnerEmail {
 		t.Errorf("want Head > OwnerEmail %q, got %q", want.Head.OwnerEmail, got.Head.OwnerEmail)
 	}
+	if got.Head.OwnerId != want.Head.OwnerId {
+		t.Errorf("want Head > OwnerId %q, got %q", want.Head.OwnerId, got.Head.OwnerId)
+	}
+	if got.Head.Docs != want.Head.Docs {
+		t.Errorf("want Head > Docs %q, got %q", want.Head.Docs, got.Head.Docs)
+	}
 	if got.Head.VertScrollState != want.Head.VertScrollState {
 		t.Errorf("want Head > VertScrollState %d, got %d", want.Head.VertScrollState, got.Head.VertScrollState)
 	}
@@ -49,6 +65,16 @@ func AssertDocumentsEqual(t *testing.T, got, want Document) {
 		t.Errorf("want Head > WindowRight %d, got %d", want.Head.WindowRight, got.Head.WindowRight)
 	}
 
+	if len(got.Head.Elements) != len(want.Head.Elements) {
+		t.Errorf("want Head > %d Elements, got %d", len(want.Head.Elements), len(got.Head.Elements))
+	} else {
+		for index, wantElement := range want.Head.Elements {
+			if got.Head.Elements[index] != wantElement {
+				t.Errorf("want Head > Element %d %v, got %v", index, wantElement, got.Head.Elements[index])
+			}
+		}
+	}
+
 	// Body
 	AssertOutlinesEqual(t, got.Body.Outlines, want.Body.Outlines)
 }
@@ -123,6 +149,18 @@ func assertOutlinesEqual(t *testing.T, prefix string, gotOutlines, wantOutlines
 			t.Errorf("want Outline %s%d XmlUrl %q, got %q", prefix, index, wantOutline.XmlUrl, gotOutline.XmlUrl)
 		}
 
+		if len(gotOutline.Attributes) != len(wantOutline.Attributes) {
+			t.Errorf("want Outline %s%d %d Attributes, got %d", prefix, index, len(wantOutline.Attributes), len(gotOutline.Attributes))
+		} else {
+			for aIndex, wantAttr := range wantOutline.Attributes {
+				gotAttr := gotOutline.Attributes[aIndex]
+
+				if gotAttr != wantAttr {
+					t.Errorf("want Outline %s%d Attribute %d %v, got %v", prefix, index, aIndex, wantAttr, gotAttr)
+				}
+			}
+		}
+
 		childPrefix := fmt.Sprintf("%s%d.", prefix, index)
 		assertOutlinesEqual(t, childPrefix, gotOutline.Outlines, wantOutline.Outlines)
 	}
@@ -0,0 +1,197 @@
+// Copyright (c) VirtualTam
+// SPDX-License-Identifier: MIT
+
+package opml
+
+import (
+	"iter"
+	"slices"
+	"strings"
+)
+
+const categorySeparator = "/"
+
+// A Category is an Outline category, in the format defined by the RSS 2.0
+// category element: either a slash-delimited hierarchical category, e.g.
+// "/Tourism/New York", or a tag that contains no slashes, e.g. "baseball".
+type Category string
+
+// ParseCategories parses the value of a category attribute, a comma-separated
+// list of categories.
+//
+// Surrounding whitespace is trimmed, and empty categories are dropped.
+func ParseCategories(value string) []Category {
+	var categories []Category
+
+	for _, category := range strings.Split(value, ",") {
+		category = strings.TrimSpace(category)
+		if category == "" {
+			continue
+		}
+
+		categories = append(categories, Category(category))
+	}
+
+	return categories
+}
+
+// IsTag returns whether the Category is a tag, rather than a hierarchical category.
+func (c Category) IsTag() bool {
+	return !strings.Contains(string(c), categorySeparator)
+}
+
+// Segments returns the elements of a hierarchical Category, from the root;
+// surrounding whitespace is trimmed, and empty elements are dropped.
+//
+// The only segment of a tag is the tag itself.
+func (c Category) Segments() []string {
+	if c.IsTag() {
+		return []string{strings.TrimSpace(string(c))}
+	}
+
+	var segments []string
+
+	for _, segment := range strings.Split(string(c), categorySeparator) {
+		segment = strings.TrimSpace(segment)
+		if segment == "" {
+			continue
+		}
+
+		segments = append(segments, segment)
+	}
+
+	return segments
+}
+
+// Parent returns the parent of a hierarchical Category, and whether it has one.
+//
+// Top-level categories and tags have no parent.
+func (c Category) Parent() (Category, bool) {
+	if c.IsTag() {
+		return "", false
+	}
+
+	segments := c.Segments()
+	if len(segments) < 2 {
+		return "", false
+	}
+
+	return newHierarchicalCategory(segments[:len(segments)-1]), true
+}
+
+// HasPrefix returns whether the Category is equal to, or a subcategory of, prefix.
+//
+// Hierarchical categories are compared segment by segment, so that
+// "/Tourism/New York" has the prefix "/Tourism" but not "/Tour"; tags only
+// match the same tag.
+func (c Category) HasPrefix(prefix Category) bool {
+	if c.IsTag() || prefix.IsTag() {
+		return c.IsTag() && prefix.IsTag() && strings.TrimSpace(string(c)) == strings.TrimSpace(string(prefix))
+	}
+
+	segments := c.Segments()
+	prefixSegments := prefix.Segments()
+
+	return len(prefixSegments) <= len(segments) && slices.Equal(segments[:len(prefixSegments)], prefixSegments)
+}
+
+func newHierarchicalCategory(segments []string) Category {
+	return Category(categorySeparator + strings.Join(segments, categorySeparator))
+}
+
+// ParsedCategories returns the Categories of this Outline.
+func (o *Outline) ParsedCategories() []Category {
+	return ParseCategories(strings.Join(o.Categories, ","))
+}
+
+// InCategory returns an iterator over the Outlines of the Document that have
+// a Category equal to, or a subcategory of, prefix, in depth-first order.
+func (d *Document) InCategory(prefix Category) iter.Seq2[Path, *Outline] {
+	return func(yield func(Path, *Outline) bool) {
+		for path, outline := range d.All() {
+			matches := slices.ContainsFunc(outline.ParsedCategories(), func(category Category) bool {
+				return category.HasPrefix(prefix)
+			})
+
+			if matches && !yield(path, outline) {
+				return
+			}
+		}
+	}
+}
+
+// A CategoryNode is a hierarchical Category of a Document, with its subcategories.
+type CategoryNode struct {
+	// The last segment of the Category.
+	Name string `json:"name"`
+
+	// The Category.
+	Category Category `json:"category"`
+
+	// The number of Outlines in the Category or its subcategories.
+	Count int `json:"count"`
+
+	// The subcategories, in order of first appearance.
+	Children []CategoryNode `json:"children,omitempty"`
+}
+
+// CategoryTree returns the hierarchy of the hierarchical Categories of the
+// Document, in order of first appearance.
+func (d *Document) CategoryTree() []CategoryNode {
+	var roots []CategoryNode
+
+	for _, outline := range d.All() {
+		// Count each Outline once per node, even if several of its categories
+		// share a common ancestor
+		counted := make(map[Category]bool)
+
+		for _, category := range outline.ParsedCategories() {
+			if category.IsTag() {
+				continue
+			}
+
+			nodes := &roots
+			segments := category.Segments()
+
+			for i, segment := range segments {
+				index := slices.IndexFunc(*nodes, func(node CategoryNode) bool {
+					return node.Name == segment
+				})
+
+				if index < 0 {
+					*nodes = append(*nodes, CategoryNode{
+						Name:     segment,
+						Category: newHierarchicalCategory(segments[:i+1]),
+					})
+					index = len(*nodes) - 1
+				}
+
+				node := &(*nodes)[index]
+
+				if !counted[node.Category] {
+					counted[node.Category] = true
+					node.Count++
+				}
+
+				nodes = &node.Children
+			}
+		}
+	}
+
+	return roots
+}
+
+// Tags returns the tags of the Outlines of the Document, in order of first appearance.
+func (d *Document) Tags() []Category {
+	var tags []Category
+
+	for _, outline := range d.All() {
+		for _, category := range outline.ParsedCategories() {
+			if category.IsTag() && !slices.Contains(tags, category) {
+				tags = append(tags, category)
+			}
+		}
+	}
+
+	return tags
+}
@@ -0,0 +1,291 @@
+// Copyright (c) VirtualTam
+// SPDX-License-Identifier: MIT
+
+package opml
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestParseCategories(t *testing.T) {
+	cases := []struct {
+		tname string
+		input string
+		want  []Category
+	}{
+		{
+			tname: "empty",
+			input: "",
+		},
+		{
+			tname: "spec example",
+			input: "/Philosophy/Baseball/Mets,/Tourism/New York",
+			want:  []Category{"/Philosophy/Baseball/Mets", "/Tourism/New York"},
+		},
+		{
+			tname: "tags",
+			input: "baseball,travel",
+			want:  []Category{"baseball", "travel"},
+		},
+		{
+			tname: "surrounding whitespace",
+			input: " /Philosophy/Baseball/Mets , baseball\t",
+			want:  []Category{"/Philosophy/Baseball/Mets", "baseball"},
+		},
+		{
+			tname: "empty categories",
+			input: ",baseball,, ,",
+			want:  []Category{"baseball"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.tname, func(t *testing.T) {
+			got := ParseCategories(tc.input)
+
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestCategory(t *testing.T) {
+	cases := []struct {
+		tname        string
+		category     Category
+		wantTag      bool
+		wantSegments []string
+		wantParent   Category
+	}{
+		{
+			tname:        "tag",
+			category:     "baseball",
+			wantTag:      true,
+			wantSegments: []string{"baseball"},
+		},
+		{
+			tname:        "top-level category",
+			category:     "/Tourism",
+			wantSegments: []string{"Tourism"},
+		},
+		{
+			tname:        "subcategory",
+			category:     "/Philosophy/Baseball/Mets",
+			wantSegments: []string{"Philosophy", "Baseball", "Mets"},
+			wantParent:   "/Philosophy/Baseball",
+		},
+		{
+			tname:        "untidy subcategory",
+			category:     "Tourism/ New York /",
+			wantSegments: []string{"Tourism", "New York"},
+			wantParent:   "/Tourism",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.tname, func(t *testing.T) {
+			if got := tc.category.IsTag(); got != tc.wantTag {
+				t.Errorf("want IsTag %t, got %t", tc.wantTag, got)
+			}
+
+			if got := tc.category.Segments(); !slices.Equal(got, tc.wantSegments) {
+				t.Errorf("want Segments %q, got %q", tc.wantSegments, got)
+			}
+
+			gotParent, ok := tc.category.Parent()
+			if ok != (tc.wantParent != "") {
+				t.Errorf("want parent %t, got %t", tc.wantParent != "", ok)
+			}
+			if gotParent != tc.wantParent {
+				t.Errorf("want Parent %q, got %q", tc.wantParent, gotParent)
+			}
+		})
+	}
+}
+
+func TestCategoryHasPrefix(t *testing.T) {
+	cases := []struct {
+		category Category
+		prefix   Category
+		want     bool
+	}{
+		{category: "/Philosophy/Baseball/Mets", prefix: "/Philosophy/Baseball/Mets", want: true},
+		{category: "/Philosophy/Baseball/Mets", prefix: "/Philosophy/Baseball", want: true},
+		{category: "/Philosophy/Baseball/Mets", prefix: "/Philosophy", want: true},
+		{category: "/Philosophy/Baseball/Mets", prefix: "/", want: true},
+		{category: "/Philosophy/Baseball/Mets", prefix: "/Philosophy/Base", want: false},
+		{category: "/Philosophy/Baseball", prefix: "/Philosophy/Baseball/Mets", want: false},
+		{category: "/Philosophy/Baseball", prefix: "Philosophy", want: false},
+		{category: "baseball", prefix: "baseball", want: true},
+		{category: "baseball", prefix: "/baseball", want: false},
+		{category: "baseball", prefix: "base", want: false},
+	}
+
+	for _, tc := range cases {
+		t.Run(fmt.Sprintf("%s has prefix %s", tc.category, tc.prefix), func(t *testing.T) {
+			if got := tc.category.HasPrefix(tc.prefix); got != tc.want {
+				t.Errorf("want %t, got %t", tc.want, got)
+			}
+		})
+	}
+}
+
+// categoryOutlines are appended to a copy of specDocumentCategory, adding tags
+// and nested Outlines to the categories of the specification example.
+var categoryOutlines = []Outline{
+	{
+		Text:       "Baseball",
+		Categories: []string{"/Philosophy/Baseball", "sports"},
+		Outlines: []Outline{
+			{
+				Text:       "The Yankees are the best team in baseball.",
+				Categories: []string{"/Philosophy/Baseball/Yankees", "/Philosophy/Baseball/Mets", "sports"},
+			},
+		},
+	},
+	{
+		Text:       "Central Park",
+		Categories: []string{"/Tourism/New York", "parks"},
+	},
+}
+
+func TestDocumentInCategory(t *testing.T) {
+	cases := []struct {
+		tname  string
+		prefix Category
+		want   []string
+	}{
+		{
+			tname:  "category",
+			prefix: "/Philosophy/Baseball/Mets",
+			want: []string{
+				"0 The Mets are the best team in baseball.",
+				"1.0 The Yankees are the best team in baseball.",
+			},
+		},
+		{
+			tname:  "subcategories",
+			prefix: "/Philosophy",
+			want: []string{
+				"0 The Mets are the best team in baseball.",
+				"1 Baseball",
+				"1.0 The Yankees are the best team in baseball.",
+			},
+		},
+		{
+			tname:  "tag",
+			prefix: "parks",
+			want: []string{
+				"2 Central Park",
+			},
+		},
+		{
+			tname:  "no match",
+			prefix: "/Tourism/Boston",
+		},
+	}
+
+	document := cloneDocument(&specDocumentCategory)
+	document.Body.Outlines = append(document.Body.Outlines, cloneOutlines(categoryOutlines)...)
+
+	for _, tc := range cases {
+		t.Run(tc.tname, func(t *testing.T) {
+			got := collectOutlines(document.InCategory(tc.prefix))
+
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestDocumentCategoryTree(t *testing.T) {
+	document := cloneDocument(&specDocumentCategory)
+	document.Body.Outlines = append(document.Body.Outlines, cloneOutlines(categoryOutlines)...)
+
+	got := document.CategoryTree()
+
+	want := []CategoryNode{
+		{
+			Name:     "Philosophy",
+			Category: "/Philosophy",
+			Count:    3,
+			Children: []CategoryNode{
+				{
+					Name:     "Baseball",
+					Category: "/Philosophy/Baseball",
+					Count:    3,
+					Children: []CategoryNode{
+						{Name: "Mets", Category: "/Philosophy/Baseball/Mets", Count: 2},
+						{Name: "Yankees", Category: "/Philosophy/Baseball/Yankees", Count: 1},
+					},
+				},
+			},
+		},
+		{
+			Name:     "Tourism",
+			Category: "/Tourism",
+			Count:    2,
+			Children: []CategoryNode{
+				{Name: "New York", Category: "/Tourism/New York", Count: 2},
+			},
+		},
+	}
+
+	assertCategoryNodesEqual(t, "", got, want)
+}
+
+func assertCategoryNodesEqual(t *testing.T, prefix string, got, want []CategoryNode) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("want %d CategoryNodes under %q, got %d", len(want), prefix, len(got))
+	}
+
+	for index, wantNode := range want {
+		gotNode := got[index]
+
+		if gotNode.Name != wantNode.Name {
+			t.Errorf("want CategoryNode %s%d Name %q, got %q", prefix, index, wantNode.Name, gotNode.Name)
+		}
+		if gotNode.Category != wantNode.Category {
+			t.Errorf("want CategoryNode %s%d Category %q, got %q", prefix, index, wantNode.Category, gotNode.Category)
+		}
+		if gotNode.Count != wantNode.Count {
+			t.Errorf("want CategoryNode %s%d Count %d, got %d", prefix, index, wantNode.Count, gotNode.Count)
+		}
+
+		assertCategoryNodesEqual(t, fmt.Sprintf("%s%d.", prefix, index), gotNode.Children, wantNode.Children)
+	}
+}
+
+func TestDocumentTags(t *testing.T) {
+	document := cloneDocument(&specDocumentCategory)
+	document.Body.Outlines = append(document.Body.Outlines, cloneOutlines(categoryOutlines)...)
+
+	got := document.Tags()
+	want := []Category{"sports", "parks"}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestUnmarshalCategories(t *testing.T) {
+	document, err := UnmarshalString(`<opml version="2.0"><body>
+	<outline text="The Mets are the best team in baseball." category=" /Philosophy/Baseball/Mets , /Tourism/New York,,baseball "/>
+</body></opml>`)
+	if err != nil {
+		t.Fatalf("want no error, got %q", err)
+	}
+
+	got := document.Body.Outlines[0].Categories
+	want := []string{"/Philosophy/Baseball/Mets", "/Tourism/New York", "baseball"}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("want Categories %q, got %q", want, got)
+	}
+}
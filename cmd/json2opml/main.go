@@ -0,0 +1,41 @@
+// Copyright (c) VirtualTam
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/virtualtam/opml-go"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		log.Fatal("missing input filename")
+	}
+
+	filePath := os.Args[1]
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		fmt.Println("failed to read file:", err)
+		os.Exit(1)
+	}
+
+	var document opml.Document
+	if err := json.Unmarshal(data, &document); err != nil {
+		fmt.Println("failed to unmarshal document:", err)
+		os.Exit(1)
+	}
+
+	m, err := opml.Marshal(&document)
+	if err != nil {
+		fmt.Println("failed to marshal document:", err)
+		os.Exit(1)
+	}
+
+	fmt.Print(string(m))
+}
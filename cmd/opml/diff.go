@@ -0,0 +1,71 @@
+// Copyright (c) VirtualTam
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/virtualtam/opml-go"
+)
+
+// runDiff compares two OPML files, and exits with a non-zero status if they differ.
+func runDiff(args []string) int {
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	jsonOutput := flags.Bool("json", false, "report changes as JSON")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: opml diff [-json] OLD NEW")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return 2
+	}
+
+	var documents []*opml.Document
+
+	for _, filePath := range flags.Args() {
+		document, err := opml.UnmarshalFile(filePath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: failed to unmarshal file: %s\n", filePath, err)
+			return 2
+		}
+
+		documents = append(documents, document)
+	}
+
+	changes := opml.Diff(documents[0], documents[1])
+
+	status := 0
+	if len(changes) > 0 {
+		status = 1
+	}
+
+	if *jsonOutput {
+		if changes == nil {
+			changes = opml.Changes{}
+		}
+
+		m, err := json.MarshalIndent(changes, "", "  ")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "failed to marshal changes:", err)
+			return 2
+		}
+
+		fmt.Println(string(m))
+
+		return status
+	}
+
+	fmt.Print(changes)
+
+	return status
+}
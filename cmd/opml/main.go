@@ -0,0 +1,60 @@
+// Copyright (c) VirtualTam
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+type command struct {
+	name        string
+	description string
+	run         func(args []string) int
+}
+
+var commands = []command{
+	{
+		name:        "validate",
+		description: "Check whether OPML files conform to the specification",
+		run:         runValidate,
+	},
+	{
+		name:        "merge",
+		description: "Merge OPML files into a single document",
+		run:         runMerge,
+	},
+	{
+		name:        "diff",
+		description: "Compare the outlines of two OPML files",
+		run:         runDiff,
+	},
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: opml <command> [arguments]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Commands:")
+
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.name, cmd.description)
+	}
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	for _, cmd := range commands {
+		if cmd.name == os.Args[1] {
+			os.Exit(cmd.run(os.Args[2:]))
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "unknown command %q\n", os.Args[1])
+	usage()
+	os.Exit(2)
+}
@@ -0,0 +1,69 @@
+// Copyright (c) VirtualTam
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/virtualtam/opml-go"
+)
+
+// runMerge merges OPML files, and writes the merged document to the standard
+// output or to a file.
+func runMerge(args []string) int {
+	flags := flag.NewFlagSet("merge", flag.ExitOnError)
+	outputFilePath := flags.String("o", "", "write the merged document to this file instead of the standard output")
+	title := flags.String("title", "", "set the title of the merged document")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: opml merge [-o OUTPUT] [-title TITLE] FILE...")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	var documents []*opml.Document
+
+	for _, filePath := range flags.Args() {
+		document, err := opml.UnmarshalFile(filePath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: failed to unmarshal file: %s\n", filePath, err)
+			return 1
+		}
+
+		documents = append(documents, document)
+	}
+
+	merged := opml.Merge(documents...)
+
+	if *title != "" {
+		merged.Head.Title = *title
+	}
+
+	m, err := opml.Marshal(merged)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to marshal merged document:", err)
+		return 1
+	}
+
+	if *outputFilePath == "" {
+		fmt.Print(string(m))
+		return 0
+	}
+
+	if err := os.WriteFile(*outputFilePath, m, 0644); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write merged document:", err)
+		return 1
+	}
+
+	return 0
+}
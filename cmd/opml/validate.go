@@ -0,0 +1,87 @@
+// Copyright (c) VirtualTam
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+
+	"github.com/virtualtam/opml-go"
+)
+
+type validateReport struct {
+	File        string           `json:"file"`
+	Error       string           `json:"error,omitempty"`
+	Diagnostics opml.Diagnostics `json:"diagnostics"`
+}
+
+// runValidate validates OPML files, and exits with a non-zero status if a file
+// cannot be read or contains errors.
+func runValidate(args []string) int {
+	flags := flag.NewFlagSet("validate", flag.ExitOnError)
+	jsonOutput := flags.Bool("json", false, "report diagnostics as JSON")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: opml validate [-json] FILE...")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	status := 0
+	var reports []validateReport
+
+	for _, filePath := range flags.Args() {
+		report := validateReport{
+			File:        filePath,
+			Diagnostics: opml.Diagnostics{},
+		}
+
+		document, err := opml.UnmarshalFile(filePath)
+		if err != nil {
+			report.Error = err.Error()
+			status = 1
+		} else {
+			report.Diagnostics = opml.Validate(document)
+
+			if report.Diagnostics.HasErrors() {
+				status = 1
+			}
+		}
+
+		reports = append(reports, report)
+	}
+
+	if *jsonOutput {
+		m, err := json.MarshalIndent(reports, "", "  ")
+		if err != nil {
+			fmt.Println("failed to marshal diagnostics:", err)
+			return 1
+		}
+
+		fmt.Println(string(m))
+
+		return status
+	}
+
+	for _, report := range reports {
+		if report.Error != "" {
+			fmt.Printf("%s: failed to unmarshal file: %s\n", report.File, report.Error)
+			continue
+		}
+
+		for _, diagnostic := range report.Diagnostics {
+			fmt.Printf("%s: %s\n", report.File, diagnostic)
+		}
+	}
+
+	return status
+}
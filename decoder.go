@@ -0,0 +1,405 @@
+// Copyright (c) VirtualTam
+// SPDX-License-Identifier: MIT
+
+package opml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"iter"
+
+	"golang.org/x/net/html/charset"
+)
+
+// A Position is a location in the source of a decoded document.
+type Position struct {
+	// The line number, starting at 1.
+	Line int
+
+	// The column number, starting at 1.
+	Column int
+
+	// The byte offset, starting at 0.
+	Offset int64
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("line %d, column %d", p.Line, p.Column)
+}
+
+// A DecodeError reports an error encountered while decoding an OPML document,
+// along with its location.
+type DecodeError struct {
+	// The location of the element that could not be decoded.
+	Position Position
+
+	// The Path of the Outline that could not be decoded, or of the enclosing
+	// Outline for syntax errors; nil if the error is not located in the Body.
+	Path Path
+
+	// The underlying error.
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	if e.Path == nil {
+		return fmt.Sprintf("%s: %s", e.Position, e.Err)
+	}
+
+	return fmt.Sprintf("%s: outline %s: %s", e.Position, e.Path, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// UnmarshalOptions control the decoding of an OPML document.
+//
+// The zero value decodes documents strictly, as Unmarshal.
+type UnmarshalOptions struct {
+	// Lenient enables decoding documents that contain invalid field values,
+	// such as unparsable dates or non-integer expansion states.
+	//
+	// Invalid values are left unset, and reported as warnings instead of
+	// failing. Documents that are not well-formed XML, or whose root element
+	// is not <opml>, are still returned as errors.
+	Lenient bool
+}
+
+type decoderState int
+
+const (
+	decoderStateRoot decoderState = iota
+	decoderStateHead
+	decoderStateBody
+	decoderStateDone
+)
+
+// A Decoder reads an OPML document from an input stream, yielding its Outlines
+// one at a time instead of loading the whole Document in memory.
+//
+// The Head is decoded first, then Outlines are yielded in document order: an
+// Outline is yielded before its subordinated Outlines, which are not included
+// in its Outlines field.
+type Decoder struct {
+	decoder *xml.Decoder
+	state   decoderState
+
+	document Document
+
+	// The Path of the Outline elements that are currently open.
+	path Path
+
+	// The number of Outlines yielded at each depth of the current Path.
+	counts []int
+
+	// The Position of the last token read.
+	pos Position
+
+	options  UnmarshalOptions
+	warnings []*DecodeError
+	err      error
+}
+
+// NewDecoder returns a new Decoder reading from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return NewDecoderWithOptions(r, UnmarshalOptions{})
+}
+
+// NewDecoderWithOptions returns a new Decoder reading from r, using the given options.
+func NewDecoderWithOptions(r io.Reader, options UnmarshalOptions) *Decoder {
+	decoder := xml.NewDecoder(r)
+	decoder.CharsetReader = charset.NewReaderLabel
+
+	return &Decoder{
+		decoder: decoder,
+		options: options,
+	}
+}
+
+// Head decodes and returns the Head of the document.
+//
+// Calling Head is optional; it is otherwise decoded by the first call to Next.
+func (d *Decoder) Head() (Head, error) {
+	if err := d.decodeHead(); err != nil {
+		return Head{}, err
+	}
+
+	return d.document.Head, nil
+}
+
+// Version returns the OPML version of the document.
+//
+// It is available once the Head has been decoded.
+func (d *Decoder) Version() string {
+	return d.document.Version
+}
+
+// Namespaces returns the namespaces declared on the root element of the document.
+//
+// They are available once the Head has been decoded.
+func (d *Decoder) Namespaces() []Namespace {
+	return d.document.Namespaces
+}
+
+// Next decodes and returns the next Outline of the document, along with its Path.
+//
+// The returned Outline does not contain subordinated Outlines, which are returned
+// by subsequent calls to Next.
+//
+// At the end of the document, Next returns io.EOF. Other errors are returned
+// as a *DecodeError.
+func (d *Decoder) Next() (Path, Outline, error) {
+	if err := d.decodeHead(); err != nil {
+		return nil, Outline{}, err
+	}
+
+	for d.state == decoderStateBody {
+		token, err := d.token()
+		if err != nil {
+			return nil, Outline{}, d.fail(err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if t.Name.Local != outlineStartElement.Name.Local {
+				if err := d.decoder.Skip(); err != nil {
+					return nil, Outline{}, d.fail(err)
+				}
+
+				continue
+			}
+
+			depth := len(d.path)
+			d.path = append(d.path, d.counts[depth])
+			d.counts[depth]++
+			d.counts = append(d.counts, 0)
+
+			outline, err := decodeOutlineStartElement(t, d.warnFunc(d.pos))
+			if err != nil {
+				return nil, Outline{}, d.failAt(err, d.pos)
+			}
+
+			outline.Position = d.pos
+
+			return d.path.clone(), outline, nil
+
+		case xml.EndElement:
+			if len(d.path) > 0 {
+				d.path = d.path[:len(d.path)-1]
+				d.counts = d.counts[:len(d.counts)-1]
+
+				continue
+			}
+
+			// End of the <body> element
+			if err := d.decoder.Skip(); err != nil {
+				return nil, Outline{}, d.fail(err)
+			}
+
+			d.state = decoderStateDone
+		}
+	}
+
+	return nil, Outline{}, io.EOF
+}
+
+// Outlines returns an iterator over the remaining Outlines of the document and
+// their Path.
+//
+// Iteration stops at the end of the document or at the first error, which is
+// then returned by Err.
+func (d *Decoder) Outlines() iter.Seq2[Path, Outline] {
+	return func(yield func(Path, Outline) bool) {
+		for {
+			path, outline, err := d.Next()
+			if err != nil {
+				return
+			}
+
+			if !yield(path, outline) {
+				return
+			}
+		}
+	}
+}
+
+// Err returns the first error that was encountered by the Decoder, if any.
+func (d *Decoder) Err() error {
+	return d.err
+}
+
+// Warnings returns the invalid field values that were encountered by a lenient
+// Decoder so far.
+func (d *Decoder) Warnings() []*DecodeError {
+	return d.warnings
+}
+
+// decodeRoot decodes the attributes of the <opml> root element.
+func (d *Decoder) decodeRoot(start xml.StartElement) {
+	d.document.XMLName = start.Name
+
+	for _, attr := range start.Attr {
+		switch {
+		case attr.Name.Space == xmlnsPrefix:
+			d.document.Namespaces = append(d.document.Namespaces, Namespace{Prefix: attr.Name.Local, URI: attr.Value})
+		case attr.Name.Space == "" && attr.Name.Local == xmlnsPrefix:
+			d.document.Namespaces = append(d.document.Namespaces, Namespace{URI: attr.Value})
+		case attr.Name.Space == "" && attr.Name.Local == "version":
+			d.document.Version = attr.Value
+		}
+	}
+
+	d.state = decoderStateHead
+}
+
+// decodeHead decodes the document up to the start of the <body> element.
+func (d *Decoder) decodeHead() error {
+	if d.err != nil {
+		return d.err
+	}
+
+	for d.state == decoderStateRoot || d.state == decoderStateHead {
+		token, err := d.token()
+		if err != nil {
+			return d.fail(err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if d.state == decoderStateRoot {
+				if t.Name.Local != "opml" {
+					return d.fail(fmt.Errorf("expected element type <opml> but have <%s>", t.Name.Local))
+				}
+
+				d.decodeRoot(t)
+
+				continue
+			}
+
+			switch t.Name.Local {
+			case "head":
+				headPos := d.pos
+
+				var mHead marshalableHead
+				if err := d.decoder.DecodeElement(&mHead, &t); err != nil {
+					return d.fail(err)
+				}
+
+				head, err := mHead.toHead(d.warnFunc(headPos))
+				if err != nil {
+					return d.failAt(fmt.Errorf("head: %w", err), headPos)
+				}
+
+				d.document.Head = head
+
+			case "body":
+				d.counts = []int{0}
+				d.state = decoderStateBody
+
+			default:
+				if err := d.decoder.Skip(); err != nil {
+					return d.fail(err)
+				}
+			}
+
+		case xml.EndElement:
+			// End of the <opml> element, without a <body>
+			d.state = decoderStateDone
+		}
+	}
+
+	return nil
+}
+
+// decodeDocument decodes the remainder of the document and returns the
+// corresponding Document.
+func (d *Decoder) decodeDocument() (*Document, error) {
+	if err := d.decodeHead(); err != nil {
+		return nil, err
+	}
+
+	document := d.document
+
+	for path, outline := range d.Outlines() {
+		outlines := &document.Body.Outlines
+		for _, index := range path[:len(path)-1] {
+			outlines = &(*outlines)[index].Outlines
+		}
+
+		*outlines = append(*outlines, outline)
+	}
+
+	if err := d.Err(); err != nil {
+		return nil, err
+	}
+
+	return &document, nil
+}
+
+// warnFunc returns the function reporting warnings located at the given Position,
+// or nil if the Decoder is strict.
+func (d *Decoder) warnFunc(pos Position) warnFunc {
+	if !d.options.Lenient {
+		return nil
+	}
+
+	var path Path
+	if len(d.path) > 0 {
+		path = d.path.clone()
+	}
+
+	return func(err error) {
+		if path == nil {
+			err = fmt.Errorf("head: %w", err)
+		}
+
+		d.warnings = append(d.warnings, &DecodeError{
+			Position: pos,
+			Path:     path,
+			Err:      err,
+		})
+	}
+}
+
+// token returns the next XML token, recording its Position.
+func (d *Decoder) token() (xml.Token, error) {
+	line, column := d.decoder.InputPos()
+
+	d.pos = Position{
+		Line:   line,
+		Column: column,
+		Offset: d.decoder.InputOffset(),
+	}
+
+	return d.decoder.Token()
+}
+
+// fail records and returns a DecodeError located at the current position of
+// the underlying xml.Decoder.
+func (d *Decoder) fail(err error) error {
+	line, column := d.decoder.InputPos()
+
+	return d.failAt(err, Position{
+		Line:   line,
+		Column: column,
+		Offset: d.decoder.InputOffset(),
+	})
+}
+
+// failAt records and returns a DecodeError located at the given Position.
+func (d *Decoder) failAt(err error, pos Position) error {
+	var path Path
+	if len(d.path) > 0 {
+		path = d.path.clone()
+	}
+
+	d.err = &DecodeError{
+		Position: pos,
+		Path:     path,
+		Err:      err,
+	}
+
+	return d.err
+}
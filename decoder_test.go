@@ -0,0 +1,367 @@
+// Copyright (c) VirtualTam
+// SPDX-License-Identifier: MIT
+
+package opml
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDecoderNext(t *testing.T) {
+	inputFilePath := filepath.Join("testdata", "spec", "unmarshal", "placesLived.opml")
+
+	file, err := os.Open(inputFilePath)
+	if err != nil {
+		t.Fatalf("failed to open input file: %q", err)
+	}
+	defer file.Close()
+
+	decoder := NewDecoder(file)
+
+	head, err := decoder.Head()
+	if err != nil {
+		t.Fatalf("want no error, got %q", err)
+	}
+
+	if head.Title != specDocumentPlacesLived.Head.Title {
+		t.Errorf("want Head > Title %q, got %q", specDocumentPlacesLived.Head.Title, head.Title)
+	}
+	if decoder.Version() != Version2 {
+		t.Errorf("want Version %q, got %q", Version2, decoder.Version())
+	}
+
+	want := []struct {
+		path Path
+		text string
+	}{
+		{Path{0}, "Places I've lived"},
+		{Path{0, 0}, "Boston"},
+		{Path{0, 0, 0}, "Cambridge"},
+		{Path{0, 0, 1}, "West Newton"},
+		{Path{0, 1}, "Bay Area"},
+		{Path{0, 1, 0}, "Mountain View"},
+		{Path{0, 1, 1}, "Los Gatos"},
+		{Path{0, 1, 2}, "Palo Alto"},
+		{Path{0, 1, 3}, "Woodside"},
+		{Path{0, 2}, "New Orleans"},
+		{Path{0, 2, 0}, "Uptown"},
+		{Path{0, 2, 1}, "Metairie"},
+		{Path{0, 3}, "Wisconsin"},
+		{Path{0, 3, 0}, "Madison"},
+		{Path{0, 4}, "Florida"},
+		{Path{0, 5}, "New York"},
+		{Path{0, 5, 0}, "Jackson Heights"},
+		{Path{0, 5, 1}, "Flushing"},
+		{Path{0, 5, 2}, "The Bronx"},
+	}
+
+	for _, w := range want {
+		path, outline, err := decoder.Next()
+		if err != nil {
+			t.Fatalf("want no error, got %q", err)
+		}
+
+		if !slices.Equal(path, w.path) {
+			t.Errorf("want Path %v, got %v", w.path, path)
+		}
+		if outline.Text != w.text {
+			t.Errorf("want Outline %v Text %q, got %q", w.path, w.text, outline.Text)
+		}
+		if len(outline.Outlines) > 0 {
+			t.Errorf("want Outline %v to have no subordinated Outlines, got %d", w.path, len(outline.Outlines))
+		}
+	}
+
+	if _, _, err := decoder.Next(); !errors.Is(err, io.EOF) {
+		t.Errorf("want io.EOF, got %q", err)
+	}
+}
+
+func TestDecoderOutlines(t *testing.T) {
+	cases := []struct {
+		tname     string
+		input     string
+		wantPaths []Path
+		wantErr   bool
+	}{
+		{
+			tname: "nested",
+			input: `<opml version="2.0"><head></head><body>
+<outline text="a"><outline text="a.a"/><outline text="a.b"><outline text="a.b.a"/></outline></outline>
+<outline text="b"/>
+</body></opml>`,
+			wantPaths: []Path{{0}, {0, 0}, {0, 1}, {0, 1, 0}, {1}},
+		},
+		{
+			tname:     "no head",
+			input:     `<opml version="2.0"><body><outline text="a"/></body></opml>`,
+			wantPaths: []Path{{0}},
+		},
+		{
+			tname:     "empty body",
+			input:     `<opml version="2.0"><head></head><body></body></opml>`,
+			wantPaths: nil,
+		},
+		{
+			tname: "invalid created date",
+			input: `<opml version="2.0"><head></head><body>
+<outline text="a"/><outline text="b" created="yesterday"/><outline text="c"/>
+</body></opml>`,
+			wantPaths: []Path{{0}},
+			wantErr:   true,
+		},
+		{
+			tname:   "invalid root element",
+			input:   `<html><body></body></html>`,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.tname, func(t *testing.T) {
+			decoder := NewDecoder(strings.NewReader(tc.input))
+
+			var gotPaths []Path
+			for path := range decoder.Outlines() {
+				gotPaths = append(gotPaths, path)
+			}
+
+			if !slices.EqualFunc(gotPaths, tc.wantPaths, slices.Equal) {
+				t.Errorf("want Paths %v, got %v", tc.wantPaths, gotPaths)
+			}
+
+			err := decoder.Err()
+			if tc.wantErr && err == nil {
+				t.Error("want error, got none")
+			}
+			if !tc.wantErr && err != nil {
+				t.Errorf("want no error, got %q", err)
+			}
+		})
+	}
+}
+
+func TestDocumentUnmarshalXML(t *testing.T) {
+	inputFilePath := filepath.Join("testdata", "extension", "namespaces.opml")
+
+	data, err := os.ReadFile(inputFilePath)
+	if err != nil {
+		t.Fatalf("failed to read input file: %q", err)
+	}
+
+	var got Document
+	if err := xml.Unmarshal(data, &got); err != nil {
+		t.Fatalf("want no error, got %q", err)
+	}
+
+	AssertDocumentsEqual(t, got, extensionDocumentNamespaces)
+}
+
+func TestDecoderOutlinePosition(t *testing.T) {
+	input := `<?xml version="1.0" encoding="UTF-8"?>
+<opml version="2.0">
+  <head>
+    <title>Positions</title>
+  </head>
+  <body>
+    <outline text="a">
+      <outline text="a.a"/>
+    </outline>
+    <outline text="b"/>
+  </body>
+</opml>`
+
+	want := []Position{
+		{Line: 7, Column: 5, Offset: int64(strings.Index(input, `<outline text="a">`))},
+		{Line: 8, Column: 7, Offset: int64(strings.Index(input, `<outline text="a.a"/>`))},
+		{Line: 10, Column: 5, Offset: int64(strings.Index(input, `<outline text="b"/>`))},
+	}
+
+	decoder := NewDecoder(strings.NewReader(input))
+
+	var got []Position
+	for _, outline := range decoder.Outlines() {
+		got = append(got, outline.Position)
+	}
+
+	if err := decoder.Err(); err != nil {
+		t.Fatalf("want no error, got %q", err)
+	}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("want Positions %v, got %v", want, got)
+	}
+}
+
+func TestDecodeError(t *testing.T) {
+	cases := []struct {
+		tname    string
+		input    string
+		wantLine int
+		wantPath Path
+	}{
+		{
+			tname: "invalid outline created date",
+			input: `<opml version="2.0">
+  <head></head>
+  <body>
+    <outline text="a">
+      <outline text="a.a"/>
+      <outline text="a.b" created="yesterday"/>
+    </outline>
+  </body>
+</opml>`,
+			wantLine: 6,
+			wantPath: Path{0, 1},
+		},
+		{
+			tname: "invalid head date",
+			input: `<opml version="2.0">
+  <head>
+    <dateCreated>yesterday</dateCreated>
+  </head>
+  <body></body>
+</opml>`,
+			wantLine: 2,
+		},
+		{
+			tname: "syntax error",
+			input: `<opml version="2.0">
+  <head></head>
+  <body>
+    <outline text="a">
+      <outline text="a.a">
+    </outline>
+  </body>
+</opml>`,
+			wantLine: 7,
+			wantPath: Path{0},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.tname, func(t *testing.T) {
+			_, err := UnmarshalString(tc.input)
+			if err == nil {
+				t.Fatal("want error, got none")
+			}
+
+			var decodeErr *DecodeError
+			if !errors.As(err, &decodeErr) {
+				t.Fatalf("want *DecodeError, got %T: %q", err, err)
+			}
+
+			if decodeErr.Position.Line != tc.wantLine {
+				t.Errorf("want Line %d, got %d", tc.wantLine, decodeErr.Position.Line)
+			}
+			if !slices.Equal(decodeErr.Path, tc.wantPath) {
+				t.Errorf("want Path %v, got %v", tc.wantPath, decodeErr.Path)
+			}
+		})
+	}
+}
+
+func TestUnmarshalWithOptionsLenient(t *testing.T) {
+	input := `<opml version="2.0">
+  <head>
+    <title>Messy export</title>
+    <dateCreated>yesterday</dateCreated>
+    <dateModified>Mon, 27 Feb 2006 12:11:44 GMT</dateModified>
+    <expansionState>1, two, 3</expansionState>
+    <vertScrollState> 4 </vertScrollState>
+    <windowTop>abc</windowTop>
+  </head>
+  <body>
+    <outline text="a" created="last week" isComment="maybe">
+      <outline text="a.a" created="Mon, 31 Oct 2005 18:21:33 GMT"/>
+    </outline>
+  </body>
+</opml>`
+
+	t.Run("strict", func(t *testing.T) {
+		_, warnings, err := UnmarshalWithOptions([]byte(input), UnmarshalOptions{})
+		if err == nil {
+			t.Error("want error, got none")
+		}
+		if len(warnings) > 0 {
+			t.Errorf("want no warnings, got %v", warnings)
+		}
+	})
+
+	t.Run("lenient", func(t *testing.T) {
+		got, warnings, err := UnmarshalStringWithOptions(input, UnmarshalOptions{Lenient: true})
+		if err != nil {
+			t.Fatalf("want no error, got %q", err)
+		}
+
+		want := Document{
+			XMLName: xml.Name{Local: "opml"},
+			Version: Version2,
+			Head: Head{
+				Title:           "Messy export",
+				DateModified:    mustDecodeRFC1123Time("Mon, 27 Feb 2006 12:11:44 GMT"),
+				ExpansionState:  []int{1, 3},
+				VertScrollState: 4,
+			},
+			Body: Body{
+				Outlines: []Outline{
+					{
+						Text: "a",
+						Outlines: []Outline{
+							{Text: "a.a", Created: mustDecodeRFC1123Time("Mon, 31 Oct 2005 18:21:33 GMT")},
+						},
+					},
+				},
+			},
+		}
+
+		AssertDocumentsEqual(t, *got, want)
+
+		if !slices.Equal(got.Head.ExpansionState, want.Head.ExpansionState) {
+			t.Errorf("want Head > ExpansionState %v, got %v", want.Head.ExpansionState, got.Head.ExpansionState)
+		}
+
+		wantWarnings := []struct {
+			line int
+			path Path
+		}{
+			{line: 2},
+			{line: 2},
+			{line: 2},
+			{line: 11, path: Path{0}},
+			{line: 11, path: Path{0}},
+		}
+
+		if len(warnings) != len(wantWarnings) {
+			t.Fatalf("want %d warnings, got %d: %v", len(wantWarnings), len(warnings), warnings)
+		}
+
+		for index, wantWarning := range wantWarnings {
+			if warnings[index].Position.Line != wantWarning.line {
+				t.Errorf("want warning %d Line %d, got %d", index, wantWarning.line, warnings[index].Position.Line)
+			}
+			if !slices.Equal(warnings[index].Path, wantWarning.path) {
+				t.Errorf("want warning %d Path %v, got %v", index, wantWarning.path, warnings[index].Path)
+			}
+		}
+	})
+}
+
+func TestUnmarshalFileWithOptions(t *testing.T) {
+	got, warnings, err := UnmarshalFileWithOptions("testdata/feedreader/newsblur.opml", UnmarshalOptions{Lenient: true})
+	if err != nil {
+		t.Fatalf("want no error, got %q", err)
+	}
+	if len(warnings) > 0 {
+		t.Errorf("want no warnings, got %v", warnings)
+	}
+
+	AssertDocumentsEqual(t, *got, feedReaderDocumentNewsblur)
+}
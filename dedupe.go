@@ -0,0 +1,146 @@
+// Copyright (c) VirtualTam
+// SPDX-License-Identifier: MIT
+
+package opml
+
+import (
+	"slices"
+)
+
+// A DedupePolicy defines how duplicate subscriptions are collapsed by Dedupe.
+type DedupePolicy int
+
+const (
+	// DedupeKeepFirst keeps the first subscription, in document order.
+	DedupeKeepFirst DedupePolicy = iota
+
+	// DedupeKeepRichest keeps the subscription with the most metadata, at the
+	// position of the first subscription.
+	DedupeKeepRichest
+
+	// DedupeMergeCategories keeps the first subscription, in document order,
+	// and adds the categories of the other subscriptions.
+	DedupeMergeCategories
+)
+
+// A Duplicate lists the subscription Outlines sharing the same feed URL.
+type Duplicate struct {
+	// The normalized feed URL.
+	XmlUrl string `json:"xml_url"`
+
+	// The Paths of the subscription Outlines, in document order.
+	Paths []Path `json:"paths"`
+}
+
+// Duplicates returns the subscription Outlines of the Document that share the
+// same feed URL, once normalized with NormalizeURL.
+//
+// Duplicates are listed in the order of their first subscription.
+func (d *Document) Duplicates() []Duplicate {
+	var (
+		duplicates []Duplicate
+		indexes    = make(map[string]int)
+	)
+
+	for path, outline := range d.All() {
+		if outline.OutlineType() != OutlineTypeSubscription || outline.XmlUrl == "" {
+			continue
+		}
+
+		xmlUrl := NormalizeURL(outline.XmlUrl)
+
+		index, ok := indexes[xmlUrl]
+		if !ok {
+			indexes[xmlUrl] = len(duplicates)
+			duplicates = append(duplicates, Duplicate{XmlUrl: xmlUrl, Paths: []Path{path}})
+
+			continue
+		}
+
+		duplicates[index].Paths = append(duplicates[index].Paths, path)
+	}
+
+	return slices.DeleteFunc(duplicates, func(duplicate Duplicate) bool {
+		return len(duplicate.Paths) < 2
+	})
+}
+
+// Dedupe collapses duplicate subscriptions, as reported by Duplicates, into a
+// single subscription Outline according to the given policy, and returns the
+// Duplicates that were collapsed.
+//
+// The collapsed subscription is located at the Path of the first subscription;
+// the other subscriptions are removed, and Paths reported by the returned
+// Duplicates refer to the Document before it was deduplicated.
+func (d *Document) Dedupe(policy DedupePolicy) ([]Duplicate, error) {
+	duplicates := d.Duplicates()
+
+	var removed []Path
+
+	for _, duplicate := range duplicates {
+		first, _ := d.Get(duplicate.Paths[0])
+
+		var copies []*Outline
+		for _, path := range duplicate.Paths[1:] {
+			outline, _ := d.Get(path)
+			copies = append(copies, outline)
+		}
+
+		switch policy {
+		case DedupeKeepRichest:
+			richest := first
+			for _, outline := range copies {
+				if outline.richness() > richest.richness() {
+					richest = outline
+				}
+			}
+
+			if richest != first {
+				collapsed := *richest
+				collapsed.Outlines = first.Outlines
+				*first = collapsed
+			}
+
+		case DedupeMergeCategories:
+			for _, outline := range copies {
+				for _, category := range outline.Categories {
+					if !slices.Contains(first.Categories, category) {
+						first.Categories = append(first.Categories, category)
+					}
+				}
+			}
+		}
+
+		removed = append(removed, duplicate.Paths[1:]...)
+	}
+
+	// Remove Outlines in reverse document order, so that the remaining Paths
+	// stay valid
+	slices.SortFunc(removed, slices.Compare[Path])
+
+	for _, path := range slices.Backward(removed) {
+		if _, err := d.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+
+	return duplicates, nil
+}
+
+// richness returns the number of metadata fields that are set on a
+// subscription Outline.
+func (o *Outline) richness() int {
+	richness := len(o.Categories) + len(o.Attributes)
+
+	for _, field := range []string{o.Title, o.Description, o.HtmlUrl, o.Language, string(o.Version)} {
+		if field != "" {
+			richness++
+		}
+	}
+
+	if !o.Created.IsZero() {
+		richness++
+	}
+
+	return richness
+}
@@ -0,0 +1,143 @@
+// Copyright (c) VirtualTam
+// SPDX-License-Identifier: MIT
+
+package opml
+
+import (
+	"slices"
+	"testing"
+)
+
+// duplicateSubscriptions subscribe again to the Elixir Lang and Zephyr Project
+// feeds of feedReaderDocumentFeedly, using equivalent URLs.
+var duplicateSubscriptions = []Outline{
+	{
+		Text:   "Elixir Lang",
+		Type:   OutlineTypeSubscription,
+		XmlUrl: "https://feeds.feedburner.com/ElixirLang?format=xml",
+	},
+	{
+		Text: "Unsorted",
+		Outlines: []Outline{
+			{
+				Text:        "Elixir",
+				Title:       "Elixir Lang",
+				Description: "Official blog",
+				Type:        OutlineTypeSubscription,
+				HtmlUrl:     "http://elixir-lang.org",
+				XmlUrl:      "http://feedproxy.google.com/ElixirLang?utm_source=feedly",
+				Categories:  []string{"programming", "elixir"},
+			},
+			{
+				Text:       "Zephyr",
+				Type:       OutlineTypeSubscription,
+				XmlUrl:     "http://www.zephyrproject.org/feed",
+				Categories: []string{"embedded"},
+			},
+		},
+	},
+}
+
+func TestDocumentDuplicates(t *testing.T) {
+	document := cloneDocument(&feedReaderDocumentFeedly)
+	document.Body.Outlines = append(document.Body.Outlines, cloneOutlines(duplicateSubscriptions)...)
+
+	got := document.Duplicates()
+
+	want := []Duplicate{
+		{
+			XmlUrl: "https://feeds.feedburner.com/ElixirLang",
+			Paths:  []Path{{0, 0}, {2}, {3, 0}},
+		},
+		{
+			XmlUrl: "https://www.zephyrproject.org/feed",
+			Paths:  []Path{{0, 1}, {3, 1}},
+		},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("want %d Duplicates, got %d: %v", len(want), len(got), got)
+	}
+
+	for index, wantDuplicate := range want {
+		gotDuplicate := got[index]
+
+		if gotDuplicate.XmlUrl != wantDuplicate.XmlUrl {
+			t.Errorf("want Duplicate %d XmlUrl %q, got %q", index, wantDuplicate.XmlUrl, gotDuplicate.XmlUrl)
+		}
+
+		if !slices.EqualFunc(gotDuplicate.Paths, wantDuplicate.Paths, slices.Equal) {
+			t.Errorf("want Duplicate %d Paths %v, got %v", index, wantDuplicate.Paths, gotDuplicate.Paths)
+		}
+	}
+}
+
+func TestDocumentDedupe(t *testing.T) {
+	elixir := feedReaderDocumentFeedly.Body.Outlines[0].Outlines[0]
+	zephyr := feedReaderDocumentFeedly.Body.Outlines[0].Outlines[1]
+
+	elixirCategories := elixir
+	elixirCategories.Categories = []string{"programming", "elixir"}
+
+	zephyrCategories := zephyr
+	zephyrCategories.Categories = []string{"embedded"}
+
+	cases := []struct {
+		tname      string
+		policy     DedupePolicy
+		wantElixir Outline
+		wantZephyr Outline
+	}{
+		{
+			tname:      "keep first",
+			policy:     DedupeKeepFirst,
+			wantElixir: elixir,
+			wantZephyr: zephyr,
+		},
+		{
+			tname:      "keep richest",
+			policy:     DedupeKeepRichest,
+			wantElixir: duplicateSubscriptions[1].Outlines[0],
+			wantZephyr: zephyr,
+		},
+		{
+			tname:      "merge categories",
+			policy:     DedupeMergeCategories,
+			wantElixir: elixirCategories,
+			wantZephyr: zephyrCategories,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.tname, func(t *testing.T) {
+			document := cloneDocument(&feedReaderDocumentFeedly)
+			document.Body.Outlines = append(document.Body.Outlines, cloneOutlines(duplicateSubscriptions)...)
+			document.Head.ExpansionState = []int{1, 5, 9}
+
+			duplicates, err := document.Dedupe(tc.policy)
+			if err != nil {
+				t.Fatalf("want no error, got %q", err)
+			}
+
+			if len(duplicates) != 2 {
+				t.Errorf("want 2 Duplicates, got %d", len(duplicates))
+			}
+
+			want := cloneDocument(&feedReaderDocumentFeedly)
+			want.Body.Outlines[0].Outlines[0] = tc.wantElixir
+			want.Body.Outlines[0].Outlines[1] = tc.wantZephyr
+			want.Body.Outlines = append(want.Body.Outlines, Outline{Text: "Unsorted"})
+
+			AssertDocumentsEqual(t, *document, *want)
+
+			wantExpansionState := []int{1, 5, 8}
+			if !slices.Equal(document.Head.ExpansionState, wantExpansionState) {
+				t.Errorf("want ExpansionState %v, got %v", wantExpansionState, document.Head.ExpansionState)
+			}
+
+			if remaining := document.Duplicates(); len(remaining) > 0 {
+				t.Errorf("want no remaining Duplicates, got %v", remaining)
+			}
+		})
+	}
+}
@@ -0,0 +1,307 @@
+// Copyright (c) VirtualTam
+// SPDX-License-Identifier: MIT
+
+package opml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// A ChangeType describes how an Outline differs between two Documents.
+type ChangeType string
+
+const (
+	// ChangeAdded indicates the Outline only exists in the new Document.
+	ChangeAdded ChangeType = "added"
+
+	// ChangeRemoved indicates the Outline only exists in the old Document.
+	ChangeRemoved ChangeType = "removed"
+
+	// ChangeMoved indicates the Outline belongs to a different parent in the
+	// new Document.
+	ChangeMoved ChangeType = "moved"
+
+	// ChangeModified indicates attributes of the Outline differ in the new Document.
+	ChangeModified ChangeType = "modified"
+)
+
+// An AttributeChange describes an Outline attribute that differs between two Documents.
+type AttributeChange struct {
+	// The name of the attribute, in Clark notation for extension attributes
+	// belonging to a namespace, e.g. "{http://example.org/ns}attr".
+	Name string `json:"name"`
+
+	// The value of the attribute in the old Document; empty if unset.
+	Old string `json:"old"`
+
+	// The value of the attribute in the new Document; empty if unset.
+	New string `json:"new"`
+}
+
+func (c AttributeChange) String() string {
+	return fmt.Sprintf("%s: %q -> %q", c.Name, c.Old, c.New)
+}
+
+// A Change describes an Outline that differs between two Documents.
+type Change struct {
+	// The ChangeType.
+	Type ChangeType `json:"type"`
+
+	// The Text of the Outline, in the new Document if it exists there.
+	Text string `json:"text"`
+
+	// The Path of the Outline in the old Document; nil if the Outline was added.
+	OldPath Path `json:"old_path,omitempty"`
+
+	// The Path of the Outline in the new Document; nil if the Outline was removed.
+	NewPath Path `json:"new_path,omitempty"`
+
+	// The attributes that differ, for modified Outlines.
+	Attributes []AttributeChange `json:"attributes,omitempty"`
+}
+
+func (c Change) String() string {
+	switch c.Type {
+	case ChangeAdded:
+		return fmt.Sprintf("%s: outline %s %q", c.Type, c.NewPath, c.Text)
+	case ChangeRemoved:
+		return fmt.Sprintf("%s: outline %s %q", c.Type, c.OldPath, c.Text)
+	case ChangeMoved:
+		return fmt.Sprintf("%s: outline %s -> %s %q", c.Type, c.OldPath, c.NewPath, c.Text)
+	}
+
+	attributes := make([]string, len(c.Attributes))
+	for i, attribute := range c.Attributes {
+		attributes[i] = attribute.String()
+	}
+
+	return fmt.Sprintf("%s: outline %s %q: %s", c.Type, c.NewPath, c.Text, strings.Join(attributes, ", "))
+}
+
+// Changes is a list of Change.
+type Changes []Change
+
+// String returns the text representation of the Changes, with one Change per line.
+func (cs Changes) String() string {
+	var sb strings.Builder
+
+	for _, change := range cs {
+		sb.WriteString(change.String())
+		sb.WriteByte('\n')
+	}
+
+	return sb.String()
+}
+
+// Diff returns the Changes between the Outlines of an old and a new Document.
+//
+// Outlines are matched by their normalized xmlUrl or url attribute when they
+// have one, and by TextPath otherwise, so that inserting or removing an Outline
+// does not change how its siblings are matched. An Outline is considered moved
+// when the texts of its ancestors differ.
+//
+// Removed Outlines are listed first, in the order of the old Document, followed
+// by the other Changes, in the order of the new Document.
+func Diff(a, b *Document) Changes {
+	var changes Changes
+
+	oldOutlines := indexOutlines(a)
+	newOutlines := indexOutlines(b)
+
+	for _, oldEntry := range oldOutlines.entries {
+		if _, ok := newOutlines.entry(oldEntry.key); !ok {
+			changes = append(changes, Change{
+				Type:    ChangeRemoved,
+				Text:    oldEntry.outline.Text,
+				OldPath: oldEntry.path,
+			})
+		}
+	}
+
+	for _, newEntry := range newOutlines.entries {
+		oldEntry, ok := oldOutlines.entry(newEntry.key)
+		if !ok {
+			changes = append(changes, Change{
+				Type:    ChangeAdded,
+				Text:    newEntry.outline.Text,
+				NewPath: newEntry.path,
+			})
+
+			continue
+		}
+
+		if !slices.Equal(oldEntry.parent, newEntry.parent) {
+			changes = append(changes, Change{
+				Type:    ChangeMoved,
+				Text:    newEntry.outline.Text,
+				OldPath: oldEntry.path,
+				NewPath: newEntry.path,
+			})
+		}
+
+		if attributes := diffAttributes(oldEntry.outline, newEntry.outline); len(attributes) > 0 {
+			changes = append(changes, Change{
+				Type:       ChangeModified,
+				Text:       newEntry.outline.Text,
+				OldPath:    oldEntry.path,
+				NewPath:    newEntry.path,
+				Attributes: attributes,
+			})
+		}
+	}
+
+	return changes
+}
+
+// An outlineEntry is an Outline of a Document, indexed by its matching key.
+type outlineEntry struct {
+	key     string
+	path    Path
+	parent  TextPath
+	outline *Outline
+}
+
+// outlineIndex lists the Outlines of a Document in document order, and their
+// index by matching key and by Path.
+type outlineIndex struct {
+	entries []outlineEntry
+	byKey   map[string]int
+	byPath  map[string]int
+}
+
+// entry returns the outlineEntry with the given matching key.
+func (idx outlineIndex) entry(key string) (outlineEntry, bool) {
+	i, ok := idx.byKey[key]
+	if !ok {
+		return outlineEntry{}, false
+	}
+
+	return idx.entries[i], true
+}
+
+// at returns the outlineEntry located at the given Path.
+func (idx outlineIndex) at(path Path) (outlineEntry, bool) {
+	i, ok := idx.byPath[path.String()]
+	if !ok {
+		return outlineEntry{}, false
+	}
+
+	return idx.entries[i], true
+}
+
+// indexOutlines returns the Outlines of a Document indexed by matching key.
+//
+// Outlines without a URL are matched by TextPath. Outlines sharing the same key
+// are told apart by their order of occurrence.
+func indexOutlines(d *Document) outlineIndex {
+	index := outlineIndex{
+		byKey:  make(map[string]int),
+		byPath: make(map[string]int),
+	}
+
+	occurrences := make(map[string]int)
+
+	for path, outline := range d.All() {
+		var key string
+
+		switch {
+		case outline.XmlUrl != "":
+			key = "xmlUrl " + NormalizeURL(outline.XmlUrl)
+		case outline.Url != "":
+			key = "url " + NormalizeURL(outline.Url)
+		default:
+			textPath, _ := d.TextPath(path)
+			key = "text " + textPath.String()
+		}
+
+		occurrences[key]++
+		if occurrence := occurrences[key]; occurrence > 1 {
+			key += " #" + strconv.Itoa(occurrence)
+		}
+
+		parent, _ := d.TextPath(path[:len(path)-1])
+
+		index.byKey[key] = len(index.entries)
+		index.byPath[path.String()] = len(index.entries)
+		index.entries = append(index.entries, outlineEntry{
+			key:     key,
+			path:    path,
+			parent:  parent,
+			outline: outline,
+		})
+	}
+
+	return index
+}
+
+// diffAttributes returns the attributes that differ between two Outlines, in
+// the order they are marshaled.
+//
+// Dates are compared by value, as the same instant may be written in different
+// layouts.
+func diffAttributes(a, b *Outline) []AttributeChange {
+	oldAttrs := outlineAttrs(a)
+	newAttrs := outlineAttrs(b)
+
+	var changes []AttributeChange
+
+	for _, oldAttr := range oldAttrs {
+		newValue := attrValue(newAttrs, oldAttr.Name)
+
+		if oldAttr.Name == createdAttrName && a.Created.Equal(b.Created) {
+			continue
+		}
+
+		if oldAttr.Value != newValue {
+			changes = append(changes, AttributeChange{
+				Name: attrName(oldAttr.Name),
+				Old:  oldAttr.Value,
+				New:  newValue,
+			})
+		}
+	}
+
+	for _, newAttr := range newAttrs {
+		if !slices.ContainsFunc(oldAttrs, func(attr xml.Attr) bool { return attr.Name == newAttr.Name }) {
+			changes = append(changes, AttributeChange{
+				Name: attrName(newAttr.Name),
+				New:  newAttr.Value,
+			})
+		}
+	}
+
+	return changes
+}
+
+var createdAttrName = xml.Name{Local: "created"}
+
+// outlineAttrs returns the marshaled attributes of an Outline.
+func outlineAttrs(o *Outline) []xml.Attr {
+	mOutline := newMarshalableOutline(o)
+
+	return mOutline.xmlAttrs(nil)
+}
+
+func attrValue(attrs []xml.Attr, name xml.Name) string {
+	for _, attr := range attrs {
+		if attr.Name == name {
+			return attr.Value
+		}
+	}
+
+	return ""
+}
+
+// attrName returns the name of an attribute, in Clark notation if it belongs
+// to a namespace.
+func attrName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+
+	return "{" + name.Space + "}" + name.Local
+}
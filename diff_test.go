@@ -0,0 +1,380 @@
+// Copyright (c) VirtualTam
+// SPDX-License-Identifier: MIT
+
+package opml
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"slices"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	cases := []struct {
+		tname string
+		a     *Document
+		b     *Document
+		want  Changes
+	}{
+		{
+			tname: "identical",
+			a:     cloneDocument(&walkDocument),
+			b:     cloneDocument(&walkDocument),
+		},
+		{
+			tname: "added",
+			a: &Document{
+				Body: Body{
+					Outlines: []Outline{
+						{Text: "Lobsters", Type: OutlineTypeSubscription, XmlUrl: "https://lobste.rs/rss"},
+					},
+				},
+			},
+			b: &Document{
+				Body: Body{
+					Outlines: []Outline{
+						{Text: "Go", Type: OutlineTypeSubscription, XmlUrl: "https://go.dev/blog/feed.atom"},
+						{Text: "Lobsters", Type: OutlineTypeSubscription, XmlUrl: "http://lobste.rs/rss/"},
+					},
+				},
+			},
+			want: Changes{
+				{Type: ChangeAdded, Text: "Go", NewPath: Path{0}},
+				{
+					Type:    ChangeModified,
+					Text:    "Lobsters",
+					OldPath: Path{0},
+					NewPath: Path{1},
+					Attributes: []AttributeChange{
+						{Name: "xmlUrl", Old: "https://lobste.rs/rss", New: "http://lobste.rs/rss/"},
+					},
+				},
+			},
+		},
+		{
+			tname: "removed",
+			a: &Document{
+				Body: Body{
+					Outlines: []Outline{
+						{
+							Text: "News",
+							Outlines: []Outline{
+								{Text: "Lobsters", Type: OutlineTypeSubscription, XmlUrl: "https://lobste.rs/rss"},
+							},
+						},
+						{Text: "Unsorted"},
+					},
+				},
+			},
+			b: &Document{
+				Body: Body{
+					Outlines: []Outline{
+						{Text: "News"},
+					},
+				},
+			},
+			want: Changes{
+				{Type: ChangeRemoved, Text: "Lobsters", OldPath: Path{0, 0}},
+				{Type: ChangeRemoved, Text: "Unsorted", OldPath: Path{1}},
+			},
+		},
+		{
+			tname: "moved",
+			a: &Document{
+				Body: Body{
+					Outlines: []Outline{
+						{
+							Text: "Programming",
+							Outlines: []Outline{
+								{Text: "Lobsters", Type: OutlineTypeSubscription, XmlUrl: "https://lobste.rs/rss"},
+							},
+						},
+						{Text: "News"},
+					},
+				},
+			},
+			b: &Document{
+				Body: Body{
+					Outlines: []Outline{
+						{Text: "Programming"},
+						{
+							Text: "News",
+							Outlines: []Outline{
+								{Text: "Lobsters", Type: OutlineTypeSubscription, XmlUrl: "https://lobste.rs/rss"},
+							},
+						},
+					},
+				},
+			},
+			want: Changes{
+				{Type: ChangeMoved, Text: "Lobsters", OldPath: Path{0, 0}, NewPath: Path{1, 0}},
+			},
+		},
+		{
+			tname: "modified",
+			a: &Document{
+				Body: Body{
+					Outlines: []Outline{
+						{
+							Text:       "Lobsters",
+							Type:       OutlineTypeSubscription,
+							XmlUrl:     "https://lobste.rs/rss",
+							Categories: []string{"news"},
+						},
+					},
+				},
+			},
+			b: &Document{
+				Body: Body{
+					Outlines: []Outline{
+						{
+							Text:    "lobste.rs",
+							Type:    OutlineTypeSubscription,
+							XmlUrl:  "https://lobste.rs/rss",
+							HtmlUrl: "https://lobste.rs/",
+						},
+					},
+				},
+			},
+			want: Changes{
+				{
+					Type:    ChangeModified,
+					Text:    "lobste.rs",
+					OldPath: Path{0},
+					NewPath: Path{0},
+					Attributes: []AttributeChange{
+						{Name: "text", Old: "Lobsters", New: "lobste.rs"},
+						{Name: "category", Old: "news"},
+						{Name: "htmlUrl", New: "https://lobste.rs/"},
+					},
+				},
+			},
+		},
+		{
+			tname: "moved and modified",
+			a: &Document{
+				Body: Body{
+					Outlines: []Outline{
+						{Text: "Go", Type: OutlineTypeSubscription, XmlUrl: "https://go.dev/blog/feed.atom"},
+					},
+				},
+			},
+			b: &Document{
+				Body: Body{
+					Outlines: []Outline{
+						{
+							Text: "Programming",
+							Outlines: []Outline{
+								{Text: "The Go Blog", Type: OutlineTypeSubscription, XmlUrl: "https://go.dev/blog/feed.atom"},
+							},
+						},
+					},
+				},
+			},
+			want: Changes{
+				{Type: ChangeAdded, Text: "Programming", NewPath: Path{0}},
+				{Type: ChangeMoved, Text: "The Go Blog", OldPath: Path{0}, NewPath: Path{0, 0}},
+				{
+					Type:    ChangeModified,
+					Text:    "The Go Blog",
+					OldPath: Path{0},
+					NewPath: Path{0, 0},
+					Attributes: []AttributeChange{
+						{Name: "text", Old: "Go", New: "The Go Blog"},
+					},
+				},
+			},
+		},
+		{
+			tname: "folder renamed",
+			a: &Document{
+				Body: Body{
+					Outlines: []Outline{
+						{Text: "Programming"},
+					},
+				},
+			},
+			b: &Document{
+				Body: Body{
+					Outlines: []Outline{
+						{Text: "Development"},
+					},
+				},
+			},
+			want: Changes{
+				{Type: ChangeRemoved, Text: "Programming", OldPath: Path{0}},
+				{Type: ChangeAdded, Text: "Development", NewPath: Path{0}},
+			},
+		},
+		{
+			tname: "folder inserted",
+			a: &Document{
+				Body: Body{
+					Outlines: []Outline{
+						{Text: "Programming", Title: "Programming"},
+						{Text: "News", Title: "News"},
+					},
+				},
+			},
+			b: &Document{
+				Body: Body{
+					Outlines: []Outline{
+						{Text: "Games", Title: "Games"},
+						{Text: "Programming", Title: "Programming"},
+						{Text: "News", Title: "News"},
+					},
+				},
+			},
+			want: Changes{
+				{Type: ChangeAdded, Text: "Games", NewPath: Path{0}},
+			},
+		},
+		{
+			tname: "created in another layout",
+			a: &Document{
+				Body: Body{
+					Outlines: []Outline{
+						{
+							Text:    "Go",
+							XmlUrl:  "https://go.dev/blog/feed.atom",
+							Created: mustDecodeRFC1123Time("Mon, 02 Jan 2006 15:04:05 GMT"),
+						},
+					},
+				},
+			},
+			b: &Document{
+				Body: Body{
+					Outlines: []Outline{
+						{
+							Text:          "Go",
+							XmlUrl:        "https://go.dev/blog/feed.atom",
+							Created:       mustDecodeRFC1123Time("Mon, 02 Jan 2006 15:04:05 GMT"),
+							createdSource: newDateSource("2006-01-02T15:04:05Z", mustDecodeRFC1123Time("Mon, 02 Jan 2006 15:04:05 GMT")),
+						},
+					},
+				},
+			},
+		},
+		{
+			tname: "extension attribute",
+			a: &Document{
+				Body: Body{
+					Outlines: []Outline{
+						{Text: "Go", XmlUrl: "https://go.dev/blog/feed.atom"},
+					},
+				},
+			},
+			b: &Document{
+				Namespaces: []Namespace{{Prefix: "ex", URI: "http://example.org/ns"}},
+				Body: Body{
+					Outlines: []Outline{
+						{
+							Text:   "Go",
+							XmlUrl: "https://go.dev/blog/feed.atom",
+							Attributes: []xml.Attr{
+								{Name: xml.Name{Space: "http://example.org/ns", Local: "rating"}, Value: "5"},
+							},
+						},
+					},
+				},
+			},
+			want: Changes{
+				{
+					Type:    ChangeModified,
+					Text:    "Go",
+					OldPath: Path{0},
+					NewPath: Path{0},
+					Attributes: []AttributeChange{
+						{Name: "{http://example.org/ns}rating", New: "5"},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.tname, func(t *testing.T) {
+			got := Diff(tc.a, tc.b)
+
+			if len(got) != len(tc.want) {
+				t.Fatalf("want %d Changes, got %d:\n%s", len(tc.want), len(got), got)
+			}
+
+			for index, wantChange := range tc.want {
+				gotChange := got[index]
+
+				if gotChange.Type != wantChange.Type {
+					t.Errorf("want Change %d Type %q, got %q", index, wantChange.Type, gotChange.Type)
+				}
+				if gotChange.Text != wantChange.Text {
+					t.Errorf("want Change %d Text %q, got %q", index, wantChange.Text, gotChange.Text)
+				}
+				if !slices.Equal(gotChange.OldPath, wantChange.OldPath) {
+					t.Errorf("want Change %d OldPath %v, got %v", index, wantChange.OldPath, gotChange.OldPath)
+				}
+				if !slices.Equal(gotChange.NewPath, wantChange.NewPath) {
+					t.Errorf("want Change %d NewPath %v, got %v", index, wantChange.NewPath, gotChange.NewPath)
+				}
+				if !slices.Equal(gotChange.Attributes, wantChange.Attributes) {
+					t.Errorf("want Change %d Attributes %v, got %v", index, wantChange.Attributes, gotChange.Attributes)
+				}
+			}
+		})
+	}
+}
+
+func TestChangesString(t *testing.T) {
+	changes := Changes{
+		{Type: ChangeRemoved, Text: "Unsorted", OldPath: Path{2}},
+		{Type: ChangeAdded, Text: "Go", NewPath: Path{0, 1}},
+		{Type: ChangeMoved, Text: "Lobsters", OldPath: Path{0, 0}, NewPath: Path{1, 0}},
+		{
+			Type:    ChangeModified,
+			Text:    "lobste.rs",
+			OldPath: Path{0, 0},
+			NewPath: Path{1, 0},
+			Attributes: []AttributeChange{
+				{Name: "text", Old: "Lobsters", New: "lobste.rs"},
+				{Name: "htmlUrl", New: "https://lobste.rs/"},
+			},
+		},
+	}
+
+	want := `removed: outline 2 "Unsorted"
+added: outline 0.1 "Go"
+moved: outline 0.0 -> 1.0 "Lobsters"
+modified: outline 1.0 "lobste.rs": text: "Lobsters" -> "lobste.rs", htmlUrl: "" -> "https://lobste.rs/"
+`
+
+	if got := changes.String(); got != want {
+		t.Errorf("want:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestChangesMarshalJSON(t *testing.T) {
+	changes := Changes{
+		{Type: ChangeAdded, Text: "Go", NewPath: Path{0, 1}},
+		{
+			Type:    ChangeModified,
+			Text:    "lobste.rs",
+			OldPath: Path{0, 0},
+			NewPath: Path{1, 0},
+			Attributes: []AttributeChange{
+				{Name: "text", Old: "Lobsters", New: "lobste.rs"},
+			},
+		},
+	}
+
+	got, err := json.Marshal(changes)
+	if err != nil {
+		t.Fatalf("want no error, got %q", err)
+	}
+
+	want := `[{"type":"added","text":"Go","new_path":[0,1]},` +
+		`{"type":"modified","text":"lobste.rs","old_path":[0,0],"new_path":[1,0],"attributes":[{"name":"text","old":"Lobsters","new":"lobste.rs"}]}]`
+
+	if string(got) != want {
+		t.Errorf("want %s, got %s", want, got)
+	}
+}
@@ -0,0 +1,189 @@
+// Copyright (c) VirtualTam
+// SPDX-License-Identifier: MIT
+
+package opml
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ErrOutlineNotFound is returned when a Path does not locate an Outline.
+var ErrOutlineNotFound = errors.New("opml: outline not found")
+
+// Insert inserts outlines at the given Path, shifting the Outline currently
+// located at this Path and its next siblings.
+//
+// The last index of the Path may be equal to the number of subordinated
+// Outlines of the parent, to append outlines.
+//
+// The line numbers of the Head ExpansionState and VertScrollState are updated
+// to keep referring to the same Outlines.
+func (d *Document) Insert(path Path, outlines ...Outline) error {
+	parent, index, err := d.position(path)
+	if err != nil {
+		return err
+	}
+
+	if index > len(*parent) {
+		return fmt.Errorf("%w: %s", ErrOutlineNotFound, path)
+	}
+
+	line := lineAt(d.Body.Outlines, path)
+
+	*parent = slices.Insert(*parent, index, outlines...)
+
+	d.Head.insertLines(line, countLines(outlines), nil)
+
+	return nil
+}
+
+// Remove removes the Outline located at the given Path, along with its
+// subordinated Outlines, and returns it.
+//
+// The line numbers of the Head ExpansionState and VertScrollState are updated
+// to keep referring to the same Outlines; line numbers referring to removed
+// Outlines are dropped.
+func (d *Document) Remove(path Path) (Outline, error) {
+	outline, _, err := d.remove(path)
+
+	return outline, err
+}
+
+// Replace replaces the Outline located at the given Path, along with its
+// subordinated Outlines, and returns the replaced Outline.
+//
+// The line numbers of the Head ExpansionState and VertScrollState are updated
+// to keep referring to the same Outlines; the replacement Outline inherits the
+// expansion state of the replaced Outline.
+func (d *Document) Replace(path Path, outline Outline) (Outline, error) {
+	parent, index, err := d.position(path)
+	if err != nil {
+		return Outline{}, err
+	}
+
+	if index >= len(*parent) {
+		return Outline{}, fmt.Errorf("%w: %s", ErrOutlineNotFound, path)
+	}
+
+	line := lineAt(d.Body.Outlines, path)
+	replaced := (*parent)[index]
+
+	(*parent)[index] = outline
+
+	// Drop the line numbers of the subordinated Outlines of the replaced Outline
+	states := d.Head.removeLines(line+1, countLines(replaced.Outlines))
+	if states.vertScrollState >= 0 {
+		d.Head.VertScrollState = line
+	}
+
+	d.Head.insertLines(line+1, countLines(outline.Outlines), nil)
+
+	return replaced, nil
+}
+
+// Move moves the Outline located at the given Path, along with its subordinated
+// Outlines, to a new Path.
+//
+// As with Insert, the destination Path locates the Outline before which the
+// moved Outline is inserted, and may be under a different parent; it cannot be
+// under the moved Outline.
+//
+// The line numbers of the Head ExpansionState and VertScrollState are updated
+// to keep referring to the same Outlines, including the moved Outlines.
+func (d *Document) Move(from Path, to Path) error {
+	if len(from) > 0 && len(to) > len(from) && slices.Equal(to[:len(from)], from) {
+		return fmt.Errorf("%w: cannot move outline %s into itself", ErrInvalidPath, from)
+	}
+
+	// Locate the destination once the moved Outline is removed
+	to = to.clone()
+	if depth := len(from) - 1; depth >= 0 && len(to) > depth &&
+		slices.Equal(to[:depth], from[:depth]) && to[depth] > from[depth] {
+		to[depth]--
+	}
+
+	expansionState := slices.Clone(d.Head.ExpansionState)
+	vertScrollState := d.Head.VertScrollState
+
+	outline, states, err := d.remove(from)
+	if err != nil {
+		return err
+	}
+
+	parent, index, err := d.position(to)
+	if err == nil && index > len(*parent) {
+		err = fmt.Errorf("%w: %s", ErrOutlineNotFound, to)
+	}
+
+	if err != nil {
+		// Restore the Document in its original state
+		parent, index, _ := d.position(from)
+		*parent = slices.Insert(*parent, index, outline)
+
+		d.Head.ExpansionState = expansionState
+		d.Head.VertScrollState = vertScrollState
+
+		return err
+	}
+
+	line := lineAt(d.Body.Outlines, to)
+
+	*parent = slices.Insert(*parent, index, outline)
+
+	d.Head.insertLines(line, countLines([]Outline{outline}), &states)
+
+	return nil
+}
+
+// remove removes the Outline located at the given Path, and returns it along
+// with the Head line numbers that referred to it.
+func (d *Document) remove(path Path) (Outline, lineStates, error) {
+	parent, index, err := d.position(path)
+	if err != nil {
+		return Outline{}, lineStates{}, err
+	}
+
+	if index >= len(*parent) {
+		return Outline{}, lineStates{}, fmt.Errorf("%w: %s", ErrOutlineNotFound, path)
+	}
+
+	line := lineAt(d.Body.Outlines, path)
+	outline := (*parent)[index]
+
+	*parent = slices.Delete(*parent, index, index+1)
+
+	states := d.Head.removeLines(line, countLines([]Outline{outline}))
+	d.Head.clampVertScrollState(countLines(d.Body.Outlines))
+
+	return outline, states, nil
+}
+
+// position returns the Outlines of the parent of the given Path, and the last
+// index of the Path.
+//
+// The index is not checked against the number of Outlines of the parent.
+func (d *Document) position(path Path) (*[]Outline, int, error) {
+	if len(path) == 0 {
+		return nil, 0, fmt.Errorf("%w: empty path", ErrInvalidPath)
+	}
+
+	index := path[len(path)-1]
+	if index < 0 {
+		return nil, 0, fmt.Errorf("%w: %s", ErrOutlineNotFound, path)
+	}
+
+	parent := &d.Body.Outlines
+
+	if len(path) > 1 {
+		outline, ok := d.Get(path[:len(path)-1])
+		if !ok {
+			return nil, 0, fmt.Errorf("%w: %s", ErrOutlineNotFound, path)
+		}
+
+		parent = &outline.Outlines
+	}
+
+	return parent, index, nil
+}
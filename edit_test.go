@@ -0,0 +1,305 @@
+// Copyright (c) VirtualTam
+// SPDX-License-Identifier: MIT
+
+package opml
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestDocumentEdit(t *testing.T) {
+	cases := []struct {
+		tname               string
+		edit                func(d *Document) error
+		want                []string
+		wantExpansionState  []int
+		wantVertScrollState int
+	}{
+		{
+			tname: "insert",
+			edit: func(d *Document) error {
+				return d.Insert(Path{1}, Outline{Text: "Music", Outlines: []Outline{{Text: "Jazz"}}})
+			},
+			want: []string{
+				"0 Programming", "0.0 Elixir", "0.0.0 Elixir Lang", "0.1 Go",
+				"1 Music", "1.0 Jazz",
+				"2 News", "2.0 Lobsters", "3 Unsorted",
+			},
+			wantExpansionState:  []int{1, 2, 7},
+			wantVertScrollState: 4,
+		},
+		{
+			tname: "insert several outlines before the scrolled line",
+			edit: func(d *Document) error {
+				return d.Insert(Path{0, 1}, Outline{Text: "C"}, Outline{Text: "Erlang"})
+			},
+			want: []string{
+				"0 Programming", "0.0 Elixir", "0.0.0 Elixir Lang",
+				"0.1 C", "0.2 Erlang",
+				"0.3 Go", "1 News", "1.0 Lobsters", "2 Unsorted",
+			},
+			wantExpansionState:  []int{1, 2, 7},
+			wantVertScrollState: 6,
+		},
+		{
+			tname: "append to a parent",
+			edit: func(d *Document) error {
+				return d.Insert(Path{0, 2}, Outline{Text: "Rust"})
+			},
+			want: []string{
+				"0 Programming", "0.0 Elixir", "0.0.0 Elixir Lang", "0.1 Go",
+				"0.2 Rust",
+				"1 News", "1.0 Lobsters", "2 Unsorted",
+			},
+			wantExpansionState:  []int{1, 2, 6},
+			wantVertScrollState: 4,
+		},
+		{
+			tname: "append to the body",
+			edit: func(d *Document) error {
+				return d.Insert(Path{3}, Outline{Text: "Archive"})
+			},
+			want: []string{
+				"0 Programming", "0.0 Elixir", "0.0.0 Elixir Lang", "0.1 Go",
+				"1 News", "1.0 Lobsters", "2 Unsorted",
+				"3 Archive",
+			},
+			wantExpansionState:  []int{1, 2, 5},
+			wantVertScrollState: 4,
+		},
+		{
+			tname: "remove",
+			edit: func(d *Document) error {
+				_, err := d.Remove(Path{0, 0})
+				return err
+			},
+			want: []string{
+				"0 Programming", "0.0 Go",
+				"1 News", "1.0 Lobsters", "2 Unsorted",
+			},
+			wantExpansionState:  []int{1, 3},
+			wantVertScrollState: 2,
+		},
+		{
+			tname: "remove the scrolled line",
+			edit: func(d *Document) error {
+				_, err := d.Remove(Path{0})
+				return err
+			},
+			want: []string{
+				"0 News", "0.0 Lobsters", "1 Unsorted",
+			},
+			wantExpansionState:  []int{1},
+			wantVertScrollState: 1,
+		},
+		{
+			tname: "remove the last lines",
+			edit: func(d *Document) error {
+				for range 3 {
+					if _, err := d.Remove(Path{0}); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			wantExpansionState:  nil,
+			wantVertScrollState: 0,
+		},
+		{
+			tname: "replace",
+			edit: func(d *Document) error {
+				_, err := d.Replace(Path{0, 0}, Outline{Text: "Erlang"})
+				return err
+			},
+			want: []string{
+				"0 Programming", "0.0 Erlang", "0.1 Go",
+				"1 News", "1.0 Lobsters", "2 Unsorted",
+			},
+			wantExpansionState:  []int{1, 2, 4},
+			wantVertScrollState: 3,
+		},
+		{
+			tname: "replace the parent of the scrolled line",
+			edit: func(d *Document) error {
+				_, err := d.Replace(Path{0}, Outline{Text: "Code", Outlines: []Outline{{Text: "Go"}}})
+				return err
+			},
+			want: []string{
+				"0 Code", "0.0 Go",
+				"1 News", "1.0 Lobsters", "2 Unsorted",
+			},
+			wantExpansionState:  []int{1, 3},
+			wantVertScrollState: 1,
+		},
+		{
+			tname: "move to a previous position",
+			edit: func(d *Document) error {
+				return d.Move(Path{1}, Path{0, 0})
+			},
+			want: []string{
+				"0 Programming",
+				"0.0 News", "0.0.0 Lobsters",
+				"0.1 Elixir", "0.1.0 Elixir Lang", "0.2 Go",
+				"1 Unsorted",
+			},
+			wantExpansionState:  []int{1, 2, 4},
+			wantVertScrollState: 6,
+		},
+		{
+			tname: "move to a next sibling position",
+			edit: func(d *Document) error {
+				return d.Move(Path{0}, Path{2})
+			},
+			want: []string{
+				"0 News", "0.0 Lobsters",
+				"1 Programming", "1.0 Elixir", "1.0.0 Elixir Lang", "1.1 Go",
+				"2 Unsorted",
+			},
+			wantExpansionState:  []int{1, 3, 4},
+			wantVertScrollState: 6,
+		},
+		{
+			tname: "move under a next sibling",
+			edit: func(d *Document) error {
+				return d.Move(Path{0}, Path{1, 1})
+			},
+			want: []string{
+				"0 News", "0.0 Lobsters",
+				"0.1 Programming", "0.1.0 Elixir", "0.1.0.0 Elixir Lang", "0.1.1 Go",
+				"1 Unsorted",
+			},
+			wantExpansionState:  []int{1, 3, 4},
+			wantVertScrollState: 6,
+		},
+		{
+			tname: "move the scrolled line to a next position",
+			edit: func(d *Document) error {
+				return d.Move(Path{0, 1}, Path{2})
+			},
+			want: []string{
+				"0 Programming", "0.0 Elixir", "0.0.0 Elixir Lang",
+				"1 News", "1.0 Lobsters",
+				"2 Go",
+				"3 Unsorted",
+			},
+			wantExpansionState:  []int{1, 2, 4},
+			wantVertScrollState: 6,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.tname, func(t *testing.T) {
+			document := cloneDocument(&walkDocument)
+
+			if err := tc.edit(document); err != nil {
+				t.Fatalf("want no error, got %q", err)
+			}
+
+			got := collectOutlines(document.All())
+
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("want %q, got %q", tc.want, got)
+			}
+
+			if !slices.Equal(document.Head.ExpansionState, tc.wantExpansionState) {
+				t.Errorf("want ExpansionState %v, got %v", tc.wantExpansionState, document.Head.ExpansionState)
+			}
+
+			if document.Head.VertScrollState != tc.wantVertScrollState {
+				t.Errorf("want VertScrollState %d, got %d", tc.wantVertScrollState, document.Head.VertScrollState)
+			}
+		})
+	}
+}
+
+func TestDocumentEditError(t *testing.T) {
+	cases := []struct {
+		tname   string
+		edit    func(d *Document) error
+		wantErr error
+	}{
+		{
+			tname: "insert at an empty path",
+			edit: func(d *Document) error {
+				return d.Insert(Path{}, Outline{Text: "Music"})
+			},
+			wantErr: ErrInvalidPath,
+		},
+		{
+			tname: "insert past the end",
+			edit: func(d *Document) error {
+				return d.Insert(Path{0, 3}, Outline{Text: "Rust"})
+			},
+			wantErr: ErrOutlineNotFound,
+		},
+		{
+			tname: "insert under a missing parent",
+			edit: func(d *Document) error {
+				return d.Insert(Path{5, 0}, Outline{Text: "Rust"})
+			},
+			wantErr: ErrOutlineNotFound,
+		},
+		{
+			tname: "remove a missing outline",
+			edit: func(d *Document) error {
+				_, err := d.Remove(Path{3})
+				return err
+			},
+			wantErr: ErrOutlineNotFound,
+		},
+		{
+			tname: "replace a missing outline",
+			edit: func(d *Document) error {
+				_, err := d.Replace(Path{0, -1}, Outline{Text: "Rust"})
+				return err
+			},
+			wantErr: ErrOutlineNotFound,
+		},
+		{
+			tname: "move a missing outline",
+			edit: func(d *Document) error {
+				return d.Move(Path{1, 1}, Path{0})
+			},
+			wantErr: ErrOutlineNotFound,
+		},
+		{
+			tname: "move to a missing position",
+			edit: func(d *Document) error {
+				return d.Move(Path{0, 1}, Path{4})
+			},
+			wantErr: ErrOutlineNotFound,
+		},
+		{
+			tname: "move into itself",
+			edit: func(d *Document) error {
+				return d.Move(Path{0}, Path{0, 0, 0})
+			},
+			wantErr: ErrInvalidPath,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.tname, func(t *testing.T) {
+			document := cloneDocument(&walkDocument)
+
+			err := tc.edit(document)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("want error %q, got %q", tc.wantErr, err)
+			}
+
+			want := cloneDocument(&walkDocument)
+
+			AssertDocumentsEqual(t, *document, *want)
+
+			if !slices.Equal(document.Head.ExpansionState, want.Head.ExpansionState) {
+				t.Errorf("want ExpansionState %v, got %v", want.Head.ExpansionState, document.Head.ExpansionState)
+			}
+
+			if document.Head.VertScrollState != want.Head.VertScrollState {
+				t.Errorf("want VertScrollState %d, got %d", want.Head.VertScrollState, document.Head.VertScrollState)
+			}
+		})
+	}
+}
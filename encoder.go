@@ -0,0 +1,474 @@
+// Copyright (c) VirtualTam
+// SPDX-License-Identifier: MIT
+
+package opml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/net/html/charset"
+)
+
+const (
+	defaultCharset string = "UTF-8"
+	defaultIndent  string = "  "
+)
+
+var (
+	errEncoderHeadEncoded    = errors.New("opml: head already encoded")
+	errEncoderHeadNotEncoded = errors.New("opml: head not encoded")
+	errEncoderClosed         = errors.New("opml: encoder closed")
+	errEncoderNoOpenOutline  = errors.New("opml: no outline to end")
+)
+
+// MarshalOptions control the XML encoding of a Document.
+//
+// The zero value produces the same output as Marshal.
+//
+// The attributes of decoded Outlines are written in their original order, so
+// that a document exported by a feed reader can be written back with the same
+// outline elements, given matching options. Comments and the spelling of the
+// XML declaration are not preserved.
+type MarshalOptions struct {
+	// Indent is the string used to indent nested elements.
+	//
+	// Defaults to two spaces.
+	Indent string
+
+	// Compact disables indentation, writing the document on a single line.
+	Compact bool
+
+	// OmitXMLDeclaration disables writing the <?xml ...?> declaration.
+	OmitXMLDeclaration bool
+
+	// Charset is the label of the character encoding of the output, e.g. "ISO-8859-1".
+	//
+	// Labels are resolved as in HTML documents, and the XML declaration names
+	// the encoding that is actually written, e.g. "windows-1252" for "ISO-8859-1".
+	// Characters that are not supported by the charset are written as numeric
+	// character references. Defaults to UTF-8.
+	Charset string
+
+	// SelfClosingOutlines enables writing Outlines that have no subordinated
+	// Outlines as self-closing elements, e.g. <outline text="..."/>.
+	SelfClosingOutlines bool
+}
+
+// An Encoder writes an OPML document to an output stream, allowing to append
+// Outlines one at a time instead of holding the whole Document in memory.
+//
+// The output of an Encoder created with NewEncoder is identical to that of Marshal.
+type Encoder struct {
+	// The output; nil when marshaling a Document with an xml.Encoder, in which
+	// case all elements are written through it.
+	w       io.Writer
+	encoder *xml.Encoder
+	ns      *namespaces
+	options MarshalOptions
+
+	// The name of the output character encoding, and the writer converting
+	// UTF-8 to it, that must be closed on Close.
+	charsetName   string
+	charsetWriter io.WriteCloser
+
+	// The string used to indent nested elements; empty for compact output.
+	//
+	// Elements are indented by the Encoder rather than by the xml.Encoder, so
+	// that self-closing elements can be written alongside its output.
+	indent string
+
+	// The elements that are currently open, starting with the <opml> root element.
+	open []xml.StartElement
+
+	// Whether the last open element has no content yet.
+	empty bool
+
+	closed bool
+}
+
+// NewEncoder returns a new Encoder writing to w.
+func NewEncoder(w io.Writer) *Encoder {
+	// The default options are always valid
+	encoder, _ := NewEncoderWithOptions(w, MarshalOptions{})
+
+	return encoder
+}
+
+// NewEncoderWithOptions returns a new Encoder writing to w, using the given options.
+//
+// It returns an error if the charset is not supported.
+func NewEncoderWithOptions(w io.Writer, options MarshalOptions) (*Encoder, error) {
+	e := &Encoder{
+		options:     options,
+		charsetName: defaultCharset,
+	}
+
+	if options.Charset != "" {
+		encoding, name := charset.Lookup(options.Charset)
+		if encoding == nil {
+			return nil, fmt.Errorf("opml: unsupported charset %q", options.Charset)
+		}
+
+		if name != "utf-8" {
+			e.charsetName = name
+			e.charsetWriter = encoding.NewEncoder().Writer(w).(io.WriteCloser)
+			w = e.charsetWriter
+		}
+	}
+
+	e.w = w
+	e.encoder = xml.NewEncoder(w)
+
+	if !options.Compact {
+		e.indent = options.Indent
+		if e.indent == "" {
+			e.indent = defaultIndent
+		}
+	}
+
+	return e, nil
+}
+
+// Encode writes the XML encoding of a Document, then closes the Encoder.
+func (e *Encoder) Encode(d *Document) error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+
+	if err := e.encodeDocument(d); err != nil {
+		return err
+	}
+
+	return e.Close()
+}
+
+// EncodeHead writes the XML declaration, the <opml> root element and the Head of
+// a Document, and opens its Body.
+//
+// The Outlines of the Document's Body are not written; they are appended by
+// subsequent calls to EncodeOutline, StartOutline and EndOutline.
+//
+// Extension attributes of appended Outlines should belong to the namespaces
+// declared by the Document, so that they are qualified with the corresponding prefix.
+func (e *Encoder) EncodeHead(d *Document) error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+
+	return e.encodeHead(d)
+}
+
+// EncodeOutline writes an Outline and its subordinated Outlines.
+func (e *Encoder) EncodeOutline(o *Outline) error {
+	if err := e.checkBodyOpen(); err != nil {
+		return err
+	}
+
+	return e.encodeOutline(o)
+}
+
+// StartOutline writes the start of an Outline element, leaving it open so that
+// subordinated Outlines can be appended until EndOutline is called.
+//
+// The subordinated Outlines of o are not written.
+func (e *Encoder) StartOutline(o *Outline) error {
+	if err := e.checkBodyOpen(); err != nil {
+		return err
+	}
+
+	mOutline := newMarshalableOutline(o)
+
+	start := outlineStartElement
+	start.Attr = mOutline.xmlAttrs(e.ns)
+
+	return e.encodeStart(start)
+}
+
+// EndOutline writes the end of the last Outline opened by StartOutline.
+func (e *Encoder) EndOutline() error {
+	if err := e.checkBodyOpen(); err != nil {
+		return err
+	}
+
+	// The <opml> and <body> elements are always open at this point
+	if len(e.open) <= 2 {
+		return errEncoderNoOpenOutline
+	}
+
+	return e.encodeEnd()
+}
+
+// Flush flushes any buffered XML to the underlying writer.
+func (e *Encoder) Flush() error {
+	return e.encoder.Flush()
+}
+
+// Close ends all open Outline elements, the Body and the <opml> root element, and
+// flushes the output to the underlying writer.
+//
+// Close does not close the underlying writer.
+func (e *Encoder) Close() error {
+	if e.closed {
+		return nil
+	}
+
+	if err := e.closeElements(); err != nil {
+		return err
+	}
+
+	e.closed = true
+
+	if err := e.Flush(); err != nil {
+		return err
+	}
+
+	if e.charsetWriter != nil {
+		return e.charsetWriter.Close()
+	}
+
+	return nil
+}
+
+func (e *Encoder) writeHeader() error {
+	if e.open != nil || e.closed {
+		return errEncoderHeadEncoded
+	}
+
+	if e.options.OmitXMLDeclaration {
+		return nil
+	}
+
+	header := `<?xml version="1.0" encoding="` + e.charsetName + `"?>`
+	if !e.options.Compact {
+		header += "\n"
+	}
+
+	_, err := io.WriteString(e.w, header)
+
+	return err
+}
+
+// encodeDocument writes the elements of a Document, without the XML declaration.
+func (e *Encoder) encodeDocument(d *Document) error {
+	if err := e.encodeHead(d); err != nil {
+		return err
+	}
+
+	for i := range d.Body.Outlines {
+		if err := e.EncodeOutline(&d.Body.Outlines[i]); err != nil {
+			return err
+		}
+	}
+
+	return e.closeElements()
+}
+
+func (e *Encoder) encodeHead(d *Document) error {
+	if e.open != nil || e.closed {
+		return errEncoderHeadEncoded
+	}
+
+	e.ns = newNamespaces(d)
+
+	start := xml.StartElement{
+		Name: xml.Name{Local: "opml"},
+		Attr: append(
+			[]xml.Attr{{Name: xml.Name{Local: "version"}, Value: d.Version}},
+			e.ns.declarations()...,
+		),
+	}
+
+	if err := e.encodeStart(start); err != nil {
+		return err
+	}
+
+	if err := e.encodeHeadElement(newMarshalableHead(&d.Head, e.ns)); err != nil {
+		return err
+	}
+
+	return e.encodeStart(xml.StartElement{Name: xml.Name{Local: "body"}})
+}
+
+func (e *Encoder) checkBodyOpen() error {
+	if e.closed {
+		return errEncoderClosed
+	}
+
+	if e.open == nil {
+		return errEncoderHeadNotEncoded
+	}
+
+	return nil
+}
+
+// encodeHeadElement writes the <head> element, indented at the current depth.
+func (e *Encoder) encodeHeadElement(mHead marshalableHead) error {
+	if e.indent != "" {
+		if err := e.encoder.EncodeToken(xml.CharData("\n")); err != nil {
+			return err
+		}
+	}
+
+	e.empty = false
+
+	start := xml.StartElement{Name: xml.Name{Local: "head"}}
+
+	if e.w == nil {
+		return e.encoder.EncodeElement(mHead, start)
+	}
+
+	return e.writeRaw(func(w io.Writer) error {
+		encoder := xml.NewEncoder(w)
+		if e.indent != "" {
+			encoder.Indent(strings.Repeat(e.indent, len(e.open)), e.indent)
+		}
+
+		return encoder.EncodeElement(mHead, start)
+	})
+}
+
+// encodeOutline writes an Outline and its subordinated Outlines.
+func (e *Encoder) encodeOutline(o *Outline) error {
+	mOutline := newMarshalableOutline(o)
+
+	start := outlineStartElement
+	start.Attr = mOutline.xmlAttrs(e.ns)
+
+	if len(o.Outlines) == 0 && e.options.SelfClosingOutlines {
+		return e.encodeEmpty(start)
+	}
+
+	if err := e.encodeStart(start); err != nil {
+		return err
+	}
+
+	for i := range o.Outlines {
+		if err := e.encodeOutline(&o.Outlines[i]); err != nil {
+			return err
+		}
+	}
+
+	return e.encodeEnd()
+}
+
+func (e *Encoder) encodeStart(start xml.StartElement) error {
+	// The XML declaration ends with a new line
+	if len(e.open) > 0 {
+		if err := e.writeIndent(len(e.open)); err != nil {
+			return err
+		}
+	}
+
+	if err := e.encoder.EncodeToken(start); err != nil {
+		return err
+	}
+
+	e.open = append(e.open, start)
+	e.empty = true
+
+	return nil
+}
+
+func (e *Encoder) encodeEnd() error {
+	start := e.open[len(e.open)-1]
+
+	if !e.empty {
+		if err := e.writeIndent(len(e.open) - 1); err != nil {
+			return err
+		}
+	}
+
+	if err := e.encoder.EncodeToken(start.End()); err != nil {
+		return err
+	}
+
+	e.open = e.open[:len(e.open)-1]
+	e.empty = false
+
+	return nil
+}
+
+// encodeEmpty writes an element that has no content as a self-closing element.
+//
+// The xml.Encoder always writes an end tag, so the element is written directly;
+// attributes that belong to an undeclared namespace need the xml.Encoder to
+// declare it, in which case the element is written with an end tag.
+func (e *Encoder) encodeEmpty(start xml.StartElement) error {
+	for _, attr := range start.Attr {
+		if attr.Name.Space != "" {
+			if err := e.encodeStart(start); err != nil {
+				return err
+			}
+
+			return e.encodeEnd()
+		}
+	}
+
+	if err := e.writeIndent(len(e.open)); err != nil {
+		return err
+	}
+
+	e.empty = false
+
+	return e.writeRaw(func(w io.Writer) error {
+		var buf bytes.Buffer
+
+		buf.WriteByte('<')
+		buf.WriteString(start.Name.Local)
+
+		for _, attr := range start.Attr {
+			buf.WriteByte(' ')
+			buf.WriteString(attr.Name.Local)
+			buf.WriteString(`="`)
+			if err := xml.EscapeText(&buf, []byte(attr.Value)); err != nil {
+				return err
+			}
+			buf.WriteByte('"')
+		}
+
+		buf.WriteString("/>")
+
+		_, err := w.Write(buf.Bytes())
+
+		return err
+	})
+}
+
+// writeIndent starts a new line, indented at the given depth, unless the
+// output is compact.
+func (e *Encoder) writeIndent(depth int) error {
+	if e.indent == "" {
+		return nil
+	}
+
+	return e.encoder.EncodeToken(xml.CharData("\n" + strings.Repeat(e.indent, depth)))
+}
+
+// writeRaw flushes the xml.Encoder, and calls write to write to the output directly.
+func (e *Encoder) writeRaw(write func(w io.Writer) error) error {
+	if err := e.encoder.Flush(); err != nil {
+		return err
+	}
+
+	return write(e.w)
+}
+
+// closeElements ends all open elements.
+func (e *Encoder) closeElements() error {
+	if e.open == nil {
+		return errEncoderHeadNotEncoded
+	}
+
+	for len(e.open) > 0 {
+		if err := e.encodeEnd(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,174 @@
+// Copyright (c) VirtualTam
+// SPDX-License-Identifier: MIT
+
+package opml
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// encodeOutlinesIncrementally writes Outlines with StartOutline and EndOutline,
+// one element at a time.
+func encodeOutlinesIncrementally(t *testing.T, encoder *Encoder, outlines []Outline) {
+	t.Helper()
+
+	for i := range outlines {
+		outline := outlines[i]
+
+		if !outline.IsDirectory() {
+			if err := encoder.EncodeOutline(&outline); err != nil {
+				t.Fatalf("want no error, got %q", err)
+			}
+
+			continue
+		}
+
+		if err := encoder.StartOutline(&outline); err != nil {
+			t.Fatalf("want no error, got %q", err)
+		}
+
+		encodeOutlinesIncrementally(t, encoder, outline.Outlines)
+
+		if err := encoder.EndOutline(); err != nil {
+			t.Fatalf("want no error, got %q", err)
+		}
+	}
+}
+
+func TestEncoderIncremental(t *testing.T) {
+	cases := []struct {
+		tname    string
+		document Document
+	}{
+		{
+			tname:    "spec category",
+			document: specDocumentCategory,
+		},
+		{
+			tname:    "spec places lived",
+			document: specDocumentPlacesLived,
+		},
+		{
+			tname:    "spec states",
+			document: specDocumentStates,
+		},
+		{
+			tname:    "feedly",
+			document: feedReaderDocumentFeedly,
+		},
+		{
+			tname:    "extension namespaces",
+			document: extensionDocumentNamespaces,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.tname, func(t *testing.T) {
+			want, err := Marshal(&tc.document)
+			if err != nil {
+				t.Fatalf("want no error, got %q", err)
+			}
+
+			var buf bytes.Buffer
+			encoder := NewEncoder(&buf)
+
+			if err := encoder.EncodeHead(&tc.document); err != nil {
+				t.Fatalf("want no error, got %q", err)
+			}
+
+			encodeOutlinesIncrementally(t, encoder, tc.document.Body.Outlines)
+
+			if err := encoder.Close(); err != nil {
+				t.Fatalf("want no error, got %q", err)
+			}
+
+			if got := buf.String(); got != string(want) {
+				t.Errorf("\nwant:\n%s\n\ngot:\n%s", want, got)
+			}
+		})
+	}
+}
+
+func TestEncoderCloseOpenOutlines(t *testing.T) {
+	var buf bytes.Buffer
+	encoder := NewEncoder(&buf)
+
+	document := Document{Version: Version2}
+
+	if err := encoder.EncodeHead(&document); err != nil {
+		t.Fatalf("want no error, got %q", err)
+	}
+	if err := encoder.StartOutline(&Outline{Text: "Linux"}); err != nil {
+		t.Fatalf("want no error, got %q", err)
+	}
+	if err := encoder.EncodeOutline(&Outline{Text: "Bits from Debian"}); err != nil {
+		t.Fatalf("want no error, got %q", err)
+	}
+	if err := encoder.Close(); err != nil {
+		t.Fatalf("want no error, got %q", err)
+	}
+
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<opml version="2.0">
+  <head></head>
+  <body>
+    <outline text="Linux">
+      <outline text="Bits from Debian"></outline>
+    </outline>
+  </body>
+</opml>`
+
+	if got := buf.String(); got != want {
+		t.Errorf("\nwant:\n%s\n\ngot:\n%s", want, got)
+	}
+}
+
+func TestEncoderErrors(t *testing.T) {
+	document := Document{Version: Version2}
+
+	t.Run("outline before head", func(t *testing.T) {
+		encoder := NewEncoder(&bytes.Buffer{})
+
+		if err := encoder.EncodeOutline(&Outline{Text: "Linux"}); !errors.Is(err, errEncoderHeadNotEncoded) {
+			t.Errorf("want error %q, got %q", errEncoderHeadNotEncoded, err)
+		}
+	})
+
+	t.Run("head encoded twice", func(t *testing.T) {
+		encoder := NewEncoder(&bytes.Buffer{})
+
+		if err := encoder.EncodeHead(&document); err != nil {
+			t.Fatalf("want no error, got %q", err)
+		}
+		if err := encoder.EncodeHead(&document); !errors.Is(err, errEncoderHeadEncoded) {
+			t.Errorf("want error %q, got %q", errEncoderHeadEncoded, err)
+		}
+	})
+
+	t.Run("end outline without start", func(t *testing.T) {
+		encoder := NewEncoder(&bytes.Buffer{})
+
+		if err := encoder.EncodeHead(&document); err != nil {
+			t.Fatalf("want no error, got %q", err)
+		}
+		if err := encoder.EndOutline(); !errors.Is(err, errEncoderNoOpenOutline) {
+			t.Errorf("want error %q, got %q", errEncoderNoOpenOutline, err)
+		}
+	})
+
+	t.Run("outline after close", func(t *testing.T) {
+		encoder := NewEncoder(&bytes.Buffer{})
+
+		if err := encoder.EncodeHead(&document); err != nil {
+			t.Fatalf("want no error, got %q", err)
+		}
+		if err := encoder.Close(); err != nil {
+			t.Fatalf("want no error, got %q", err)
+		}
+		if err := encoder.EncodeOutline(&Outline{Text: "Linux"}); !errors.Is(err, errEncoderClosed) {
+			t.Errorf("want error %q, got %q", errEncoderClosed, err)
+		}
+	})
+}
@@ -0,0 +1,58 @@
+// Copyright (c) VirtualTam
+// SPDX-License-Identifier: MIT
+
+package opml_test
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/virtualtam/opml-go"
+)
+
+func ExampleDecoder() {
+	blob := `<?xml version="1.0" encoding="UTF-8"?>
+<opml version="2.0">
+  <head>
+    <title>Feed subscriptions</title>
+  </head>
+  <body>
+    <outline text="Linux" title="Linux">
+      <outline text="Bits from Debian" title="Bits from Debian" type="rss" xmlUrl="https://bits.debian.org/feeds/atom.xml"></outline>
+      <outline text="KXStudio News" title="KXStudio News" type="rss" xmlUrl="https://kx.studio/News/?action=feed"></outline>
+    </outline>
+    <outline text="Social News" title="Social News">
+      <outline text="Lobsters" title="Lobsters" type="rss" xmlUrl="https://lobste.rs/rss"></outline>
+    </outline>
+  </body>
+</opml>
+`
+
+	decoder := opml.NewDecoder(strings.NewReader(blob))
+
+	head, err := decoder.Head()
+	if err != nil {
+		fmt.Println("failed to decode head:", err)
+		os.Exit(1)
+	}
+
+	fmt.Println(head.Title)
+
+	for path, outline := range decoder.Outlines() {
+		fmt.Printf("%s%v %s\n", strings.Repeat("  ", path.Depth()), path, outline.Text)
+	}
+
+	if err := decoder.Err(); err != nil {
+		fmt.Println("failed to decode outlines:", err)
+		os.Exit(1)
+	}
+
+	// Output:
+	// Feed subscriptions
+	// 0 Linux
+	//   0.0 Bits from Debian
+	//   0.1 KXStudio News
+	// 1 Social News
+	//   1.0 Lobsters
+}
@@ -0,0 +1,132 @@
+// Copyright (c) VirtualTam
+// SPDX-License-Identifier: MIT
+
+package opml
+
+import (
+	"slices"
+	"strings"
+)
+
+// FoldersToCategories returns a copy of a Document where folders are
+// converted into hierarchical categories.
+//
+// Folders are directory Outlines that are neither subscriptions nor
+// inclusions. The other Outlines are moved to the top level, in document
+// order, and the path of their folder is set as their first category, e.g.
+// "/Programming/Languages"; Outlines outside folders are left uncategorized.
+//
+// Folders are kept without their subordinated Outlines, categorized the same
+// way and followed by their flattened subordinated Outlines, so that their
+// metadata is restored by CategoriesToFolders. Folders whose text cannot be
+// written as a category segment, as it is empty, contains commas or slashes, or
+// has surrounding whitespace, are not converted, and are kept along with their
+// subordinated Outlines.
+//
+// CategoriesToFolders converts the resulting Document back into the original
+// one, as long as the Outlines outside folders have no hierarchical categories.
+// Outliner view states are not kept.
+func FoldersToCategories(d *Document) *Document {
+	converted := cloneDocument(d)
+	converted.Head.ExpansionState = nil
+	converted.Head.VertScrollState = 0
+	converted.Body.Outlines = nil
+
+	flattenFolders(&converted.Body.Outlines, nil, d.Body.Outlines)
+
+	return converted
+}
+
+// flattenFolders appends copies of the Outlines to flattened, categorized by
+// the path of their folder; folders are followed by their flattened
+// subordinated Outlines.
+func flattenFolders(flattened *[]Outline, folder []string, outlines []Outline) {
+	for _, outline := range outlines {
+		converted := cloneOutlineAttributes(outline)
+
+		if len(folder) > 0 {
+			category := string(newHierarchicalCategory(folder))
+
+			converted.Categories = slices.DeleteFunc(converted.Categories, func(c string) bool {
+				return c == category
+			})
+			converted.Categories = slices.Insert(converted.Categories, 0, category)
+		}
+
+		if isFolder(&outline) && isCategorySegment(outline.Text) {
+			*flattened = append(*flattened, converted)
+			flattenFolders(flattened, append(slices.Clip(folder), outline.Text), outline.Outlines)
+
+			continue
+		}
+
+		converted.Outlines = cloneOutlines(outline.Outlines)
+		*flattened = append(*flattened, converted)
+	}
+}
+
+// CategoriesToFolders returns a copy of a Document where the top-level
+// Outlines are grouped into folders according to their first hierarchical
+// category.
+//
+// Each segment of the category is converted into a nested folder, reusing
+// existing Outlines with the same text that are neither subscriptions nor
+// inclusions, such as the folders kept by FoldersToCategories; the category is
+// then removed from the Outline. Folders are created in order of first
+// appearance, and Outlines without hierarchical categories are left at the top
+// level. Outliner view states are not kept.
+func CategoriesToFolders(d *Document) *Document {
+	converted := cloneDocument(d)
+	converted.Head.ExpansionState = nil
+	converted.Head.VertScrollState = 0
+	converted.Body.Outlines = nil
+
+	for _, outline := range cloneOutlines(d.Body.Outlines) {
+		index := slices.IndexFunc(outline.Categories, func(c string) bool {
+			category := Category(c)
+			return !category.IsTag() && len(category.Segments()) > 0
+		})
+
+		if index < 0 {
+			converted.Body.Outlines = append(converted.Body.Outlines, outline)
+			continue
+		}
+
+		category := Category(outline.Categories[index])
+
+		outline.Categories = slices.Delete(outline.Categories, index, index+1)
+		if len(outline.Categories) == 0 {
+			outline.Categories = nil
+		}
+
+		parent := &converted.Body.Outlines
+
+		for _, segment := range category.Segments() {
+			folderIndex := slices.IndexFunc(*parent, func(o Outline) bool {
+				return o.Text == segment && o.OutlineType() != OutlineTypeSubscription && !o.IsInclusion()
+			})
+
+			if folderIndex < 0 {
+				*parent = append(*parent, Outline{Text: segment, Title: segment})
+				folderIndex = len(*parent) - 1
+			}
+
+			parent = &(*parent)[folderIndex].Outlines
+		}
+
+		*parent = append(*parent, outline)
+	}
+
+	return converted
+}
+
+// isCategorySegment returns whether a folder text can be written as a segment
+// of a hierarchical category, and parsed back unchanged.
+func isCategorySegment(text string) bool {
+	return text != "" && text == strings.TrimSpace(text) && !strings.ContainsAny(text, ","+categorySeparator)
+}
+
+// isFolder returns whether an Outline is a folder, grouping other Outlines.
+func isFolder(outline *Outline) bool {
+	return outline.IsDirectory() && outline.OutlineType() != OutlineTypeSubscription && !outline.IsInclusion()
+}
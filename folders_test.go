@@ -0,0 +1,230 @@
+// Copyright (c) VirtualTam
+// SPDX-License-Identifier: MIT
+
+package opml
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFoldersToCategories(t *testing.T) {
+	programming := extensionDocumentAttributes.Body.Outlines[0]
+
+	programmingFolder := cloneOutlineAttributes(programming)
+
+	gitRevNews := cloneOutlineAttributes(programming.Outlines[0])
+	gitRevNews.Categories = []string{"/Programming"}
+
+	goBlog := cloneOutlineAttributes(programming.Outlines[1])
+	goBlog.Categories = []string{"/Programming"}
+
+	cases := []struct {
+		tname    string
+		document *Document
+		want     []Outline
+	}{
+		{
+			tname:    "folder attributes",
+			document: &extensionDocumentAttributes,
+			want:     []Outline{programmingFolder, gitRevNews, goBlog},
+		},
+		{
+			tname:    "nested folders",
+			document: &walkDocument,
+			want: []Outline{
+				{Text: "Programming"},
+				{Text: "Elixir", Categories: []string{"/Programming"}},
+				{Text: "Elixir Lang", Categories: []string{"/Programming/Elixir"}},
+				{Text: "Go", Categories: []string{"/Programming"}},
+				{Text: "News"},
+				{Text: "Lobsters", Categories: []string{"/News"}},
+				{Text: "Unsorted"},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.tname, func(t *testing.T) {
+			document := cloneDocument(tc.document)
+
+			got := FoldersToCategories(document)
+
+			AssertOutlinesEqual(t, got.Body.Outlines, tc.want)
+
+			if got.Head.ExpansionState != nil {
+				t.Errorf("want no ExpansionState, got %v", got.Head.ExpansionState)
+			}
+
+			// Converting does not modify the input document
+			AssertDocumentsEqual(t, *document, *tc.document)
+		})
+	}
+}
+
+func TestCategoriesToFolders(t *testing.T) {
+	var categorized []Outline
+
+	for _, folder := range feedReaderDocumentFeedly.Body.Outlines {
+		for _, outline := range folder.Outlines {
+			outline = cloneOutlineAttributes(outline)
+			outline.Categories = []string{"/" + folder.Text}
+
+			categorized = append(categorized, outline)
+		}
+	}
+
+	cases := []struct {
+		tname string
+		input []Outline
+		want  []Outline
+	}{
+		{
+			tname: "categories",
+			input: categorized,
+			want:  feedReaderDocumentFeedly.Body.Outlines,
+		},
+		{
+			tname: "first hierarchical category",
+			input: []Outline{
+				{Text: "Mets", Categories: []string{"baseball", " /Philosophy/Baseball/ ", "/Tourism/New York"}},
+			},
+			want: []Outline{
+				{
+					Text:  "Philosophy",
+					Title: "Philosophy",
+					Outlines: []Outline{
+						{
+							Text:  "Baseball",
+							Title: "Baseball",
+							Outlines: []Outline{
+								{Text: "Mets", Categories: []string{"baseball", "/Tourism/New York"}},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			tname: "existing folder",
+			input: []Outline{
+				{
+					Text: "Programming",
+					Outlines: []Outline{
+						{Text: "Go", Type: OutlineTypeSubscription, XmlUrl: "https://go.dev/blog/feed.atom"},
+					},
+				},
+				{
+					Text:       "Programming",
+					Type:       OutlineTypeSubscription,
+					XmlUrl:     "https://example.org/programming.xml",
+					Categories: []string{"/Programming"},
+				},
+			},
+			want: []Outline{
+				{
+					Text: "Programming",
+					Outlines: []Outline{
+						{Text: "Go", Type: OutlineTypeSubscription, XmlUrl: "https://go.dev/blog/feed.atom"},
+						{Text: "Programming", Type: OutlineTypeSubscription, XmlUrl: "https://example.org/programming.xml"},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.tname, func(t *testing.T) {
+			document := &Document{
+				Version: Version2,
+				Body:    Body{Outlines: tc.input},
+			}
+
+			got := CategoriesToFolders(document)
+
+			AssertOutlinesEqual(t, got.Body.Outlines, tc.want)
+		})
+	}
+}
+
+func TestFoldersCategoriesRoundtrip(t *testing.T) {
+	folderMetadata := cloneDocument(&walkDocument)
+	folderMetadata.Body.Outlines[0].Outlines[0].Created = mustDecodeRFC1123Time("Mon, 31 Oct 2005 18:21:33 GMT")
+	folderMetadata.Body.Outlines[0].Outlines[0].IsComment = true
+	folderMetadata.Body.Outlines[0].Outlines[0].Categories = []string{"beam"}
+
+	commaFolder := cloneDocument(&feedReaderDocumentFeedly)
+	commaFolder.Body.Outlines[1].Text = "Games, Simulation"
+
+	slashFolder := cloneDocument(&walkDocument)
+	slashFolder.Body.Outlines[0].Outlines[0].Text = "Elixir/Erlang"
+
+	cases := []struct {
+		tname       string
+		document    *Document
+		wantFolders []string
+	}{
+		{
+			tname:    "feedly",
+			document: &feedReaderDocumentFeedly,
+		},
+		{
+			tname:    "newsblur",
+			document: &feedReaderDocumentNewsblur,
+		},
+		{
+			tname:    "nested folders",
+			document: &walkDocument,
+		},
+		{
+			tname:    "folder attributes",
+			document: &extensionDocumentAttributes,
+		},
+		{
+			tname:    "folder metadata",
+			document: folderMetadata,
+		},
+		{
+			tname:       "comma in folder text",
+			document:    commaFolder,
+			wantFolders: []string{"Games, Simulation"},
+		},
+		{
+			tname:       "slash in nested folder text",
+			document:    slashFolder,
+			wantFolders: []string{"Elixir/Erlang"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.tname, func(t *testing.T) {
+			categorized := FoldersToCategories(tc.document)
+
+			var gotFolders []string
+
+			for _, outline := range categorized.Body.Outlines {
+				if isFolder(&outline) {
+					gotFolders = append(gotFolders, outline.Text)
+				}
+
+				// Categories are parsed back unchanged once marshaled
+				parsed := outline.ParsedCategories()
+				if !slices.EqualFunc(parsed, outline.Categories, func(c Category, s string) bool { return string(c) == s }) {
+					t.Errorf("want Outline %q Categories %q to be parsed unchanged, got %q", outline.Text, outline.Categories, parsed)
+				}
+			}
+
+			if !slices.Equal(gotFolders, tc.wantFolders) {
+				t.Errorf("want folders %q, got %q", tc.wantFolders, gotFolders)
+			}
+
+			got := CategoriesToFolders(categorized)
+
+			want := *tc.document
+			want.Head.ExpansionState = nil
+			want.Head.VertScrollState = 0
+
+			AssertDocumentsEqual(t, *got, want)
+		})
+	}
+}
@@ -0,0 +1,512 @@
+// Copyright (c) VirtualTam
+// SPDX-License-Identifier: MIT
+
+package opml
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const (
+	// DefaultMaxIncludeDepth is the default maximum nesting of included documents.
+	DefaultMaxIncludeDepth = 8
+
+	// DefaultMaxFetchSize is the default maximum size of a document retrieved
+	// by an HTTPFetcher, in bytes.
+	DefaultMaxFetchSize int64 = 10 << 20
+)
+
+var (
+	// ErrIncludeCycle is returned when a document includes itself, directly or
+	// through other included documents.
+	ErrIncludeCycle = errors.New("opml: include cycle")
+
+	// ErrIncludeDepth is returned when included documents are nested deeper
+	// than the maximum include depth.
+	ErrIncludeDepth = errors.New("opml: maximum include depth exceeded")
+
+	// ErrNotInclusion is returned when expanding an Outline that does not
+	// include another document.
+	ErrNotInclusion = errors.New("opml: outline is not an inclusion")
+
+	// ErrUnexpectedStatus is returned when an HTTP server does not respond
+	// with the requested document.
+	ErrUnexpectedStatus = errors.New("opml: unexpected HTTP status")
+
+	// ErrFetchTooLarge is returned when a retrieved document exceeds the
+	// maximum size of the Fetcher.
+	ErrFetchTooLarge = errors.New("opml: document too large")
+)
+
+// A Fetcher retrieves the document located at a URL.
+type Fetcher interface {
+	Fetch(ctx context.Context, url string) (io.ReadCloser, error)
+}
+
+// FetcherFunc adapts a function to the Fetcher interface.
+type FetcherFunc func(ctx context.Context, url string) (io.ReadCloser, error)
+
+// Fetch calls f(ctx, url).
+func (f FetcherFunc) Fetch(ctx context.Context, url string) (io.ReadCloser, error) {
+	return f(ctx, url)
+}
+
+// FileFetcher retrieves documents from the local filesystem.
+//
+// URLs are file paths or file:// URLs; relative paths are resolved from Dir,
+// or from the current working directory if Dir is empty.
+type FileFetcher struct {
+	Dir string
+}
+
+// Fetch opens the file located at the given URL.
+func (f FileFetcher) Fetch(ctx context.Context, rawURL string) (io.ReadCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	filePath := rawURL
+
+	if u, err := url.Parse(rawURL); err == nil && u.Scheme == "file" {
+		filePath = u.Path
+	}
+
+	filePath = filepath.FromSlash(filePath)
+
+	if f.Dir != "" && !filepath.IsAbs(filePath) {
+		filePath = filepath.Join(f.Dir, filePath)
+	}
+
+	return os.Open(filePath)
+}
+
+// HTTPFetcher retrieves documents over HTTP.
+type HTTPFetcher struct {
+	// Client sends the HTTP requests; http.DefaultClient is used if nil.
+	Client *http.Client
+
+	// MaxSize is the maximum size of a response body, in bytes;
+	// DefaultMaxFetchSize is used if zero.
+	MaxSize int64
+}
+
+// Fetch sends a GET request to the given URL, and returns the response body.
+//
+// Reading more than MaxSize bytes from the body returns ErrFetchTooLarge.
+func (f HTTPFetcher) Fetch(ctx context.Context, rawURL string) (io.ReadCloser, error) {
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "text/x-opml, application/xml;q=0.9, text/xml;q=0.9, */*;q=0.1")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%w: %s", ErrUnexpectedStatus, resp.Status)
+	}
+
+	maxSize := f.MaxSize
+	if maxSize == 0 {
+		maxSize = DefaultMaxFetchSize
+	}
+
+	if resp.ContentLength > maxSize {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%w: %d bytes", ErrFetchTooLarge, resp.ContentLength)
+	}
+
+	return &limitedBody{
+		reader:  io.LimitReader(resp.Body, maxSize+1),
+		body:    resp.Body,
+		maxSize: maxSize,
+	}, nil
+}
+
+// limitedBody reads a response body, failing with ErrFetchTooLarge once more
+// than maxSize bytes have been read.
+type limitedBody struct {
+	reader  io.Reader
+	body    io.Closer
+	read    int64
+	maxSize int64
+	err     error
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+
+	n, err := b.reader.Read(p)
+	b.read += int64(n)
+
+	if b.read > b.maxSize {
+		b.err = fmt.Errorf("%w: more than %d bytes", ErrFetchTooLarge, b.maxSize)
+
+		// Only return the bytes within the limit
+		return max(n-int(b.read-b.maxSize), 0), b.err
+	}
+
+	return n, err
+}
+
+func (b *limitedBody) Close() error {
+	return b.body.Close()
+}
+
+// A CachingFetcher caches the documents retrieved by a Fetcher, by URL.
+//
+// It is safe for concurrent use.
+type CachingFetcher struct {
+	fetcher Fetcher
+
+	mu    sync.Mutex
+	cache map[string][]byte
+}
+
+// NewCachingFetcher returns a CachingFetcher retrieving documents with the
+// given Fetcher.
+func NewCachingFetcher(fetcher Fetcher) *CachingFetcher {
+	return &CachingFetcher{
+		fetcher: fetcher,
+		cache:   make(map[string][]byte),
+	}
+}
+
+// Fetch returns the cached document located at the given URL, retrieving it
+// if it is not cached yet.
+//
+// Errors are not cached.
+func (f *CachingFetcher) Fetch(ctx context.Context, url string) (io.ReadCloser, error) {
+	f.mu.Lock()
+	data, ok := f.cache[url]
+	f.mu.Unlock()
+
+	if ok {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+
+	rc, err := f.fetcher.Fetch(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	data, err = io.ReadAll(rc)
+	if err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	f.cache[url] = data
+	f.mu.Unlock()
+
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// Forget removes the document located at the given URL from the cache.
+func (f *CachingFetcher) Forget(url string) {
+	f.mu.Lock()
+	delete(f.cache, url)
+	f.mu.Unlock()
+}
+
+// An IncludeError describes an inclusion Outline that could not be resolved.
+type IncludeError struct {
+	// The Path of the inclusion Outline.
+	Path Path
+
+	// The resolved URL of the included document.
+	URL string
+
+	// The underlying error.
+	Err error
+}
+
+func (e *IncludeError) Error() string {
+	return fmt.Sprintf("outline %s: include %q: %s", e.Path, e.URL, e.Err)
+}
+
+func (e *IncludeError) Unwrap() error {
+	return e.Err
+}
+
+// IncludeOptions control the resolution of inclusion Outlines.
+type IncludeOptions struct {
+	// Fetcher retrieves included documents; an HTTPFetcher is used if nil.
+	Fetcher Fetcher
+
+	// BaseURL is the URL of the Document, used to resolve relative include URLs
+	// and to detect documents including themselves.
+	BaseURL string
+
+	// MaxDepth is the maximum nesting of included documents;
+	// DefaultMaxIncludeDepth is used if zero.
+	MaxDepth int
+
+	// FollowLinks resolves link Outlines whose url ends in .opml as inclusion
+	// Outlines, as recommended by the OPML specification.
+	FollowLinks bool
+}
+
+// ResolveIncludes resolves the inclusion Outlines of the Document, using the
+// given Fetcher and the default IncludeOptions.
+func (d *Document) ResolveIncludes(ctx context.Context, fetcher Fetcher) error {
+	return d.ResolveIncludesWithOptions(ctx, IncludeOptions{Fetcher: fetcher})
+}
+
+// ResolveIncludesWithOptions resolves the inclusion Outlines of the Document,
+// using the given options.
+//
+// The body of the document referenced by the url attribute of each inclusion
+// Outline, and of each link Outline whose url ends in .opml if FollowLinks is
+// set, is fetched and grafted under the inclusion Outline, and the Source
+// of grafted Outlines is set to the URL of the included document. Inclusion
+// Outlines of included documents are resolved recursively, relative to the
+// URL of their document.
+//
+// Inclusion Outlines that already have subordinated Outlines are considered
+// resolved, and are left unchanged.
+//
+// Resolution stops at the first inclusion Outline that cannot be resolved,
+// returning an IncludeError; Outlines grafted until then are kept.
+func (d *Document) ResolveIncludesWithOptions(ctx context.Context, options IncludeOptions) error {
+	r := newIncludeResolver(d, options)
+
+	var chain []string
+	if options.BaseURL != "" {
+		chain = append(chain, NormalizeURL(options.BaseURL))
+	}
+
+	return r.resolve(ctx, nil, options.BaseURL, chain, 0)
+}
+
+// Expand grafts the body of the document included by the Outline located at
+// path, using the given options, without resolving its own inclusion Outlines.
+//
+// This allows loading included documents lazily, e.g. to crawl OPML
+// directories on demand. The Outline must be an inclusion, as reported by
+// Outline.IsInclusion, regardless of FollowLinks. Its url is resolved relative
+// to the Source of the Outline, or to the BaseURL if it was not grafted from
+// an included document.
+//
+// Outlines that already have subordinated Outlines are considered expanded,
+// and are left unchanged.
+func (d *Document) Expand(ctx context.Context, path Path, options IncludeOptions) error {
+	outline, ok := d.Get(path)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrOutlineNotFound, path)
+	}
+
+	if !outline.IsInclusion() || outline.Url == "" {
+		return fmt.Errorf("%w: %s", ErrNotInclusion, path)
+	}
+
+	if len(outline.Outlines) > 0 {
+		return nil
+	}
+
+	// Find the documents enclosing the Outline from the Source of its ancestors
+	var chain []string
+	if options.BaseURL != "" {
+		chain = append(chain, NormalizeURL(options.BaseURL))
+	}
+
+	baseURL := options.BaseURL
+	depth := 0
+
+	for i := range path {
+		ancestor, _ := d.Get(path[:i+1])
+
+		if ancestor.Source != "" && ancestor.Source != baseURL {
+			baseURL = ancestor.Source
+			chain = append(chain, NormalizeURL(baseURL))
+			depth++
+		}
+	}
+
+	r := newIncludeResolver(d, options)
+
+	_, err := r.graft(ctx, path, baseURL, chain, depth)
+
+	return err
+}
+
+type includeResolver struct {
+	document    *Document
+	fetcher     Fetcher
+	maxDepth    int
+	followLinks bool
+}
+
+func newIncludeResolver(d *Document, options IncludeOptions) *includeResolver {
+	r := &includeResolver{
+		document:    d,
+		fetcher:     options.Fetcher,
+		maxDepth:    options.MaxDepth,
+		followLinks: options.FollowLinks,
+	}
+
+	if r.fetcher == nil {
+		r.fetcher = HTTPFetcher{}
+	}
+	if r.maxDepth == 0 {
+		r.maxDepth = DefaultMaxIncludeDepth
+	}
+
+	return r
+}
+
+// resolve resolves the inclusion Outlines under the Outline located at parent,
+// or the top-level inclusion Outlines if parent is empty.
+//
+// The chain lists the normalized URLs of the documents being included, from
+// the outermost one, and depth is the number of nested included documents.
+func (r *includeResolver) resolve(ctx context.Context, parent Path, baseURL string, chain []string, depth int) error {
+	for index := 0; index < len(r.outlines(parent)); index++ {
+		path := append(parent.clone(), index)
+		outline := r.outlines(parent)[index]
+
+		if r.isUnresolved(&outline) {
+			if err := r.include(ctx, path, baseURL, chain, depth); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if err := r.resolve(ctx, path, baseURL, chain, depth); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isUnresolved returns whether an Outline is an inclusion to resolve.
+func (r *includeResolver) isUnresolved(outline *Outline) bool {
+	if outline.Url == "" || len(outline.Outlines) > 0 {
+		return false
+	}
+
+	if r.followLinks {
+		return outline.IsInclusion()
+	}
+
+	return outline.OutlineType() == OutlineTypeInclusion
+}
+
+// include grafts the body of the document included by the inclusion Outline
+// located at path, and resolves its own inclusion Outlines.
+func (r *includeResolver) include(ctx context.Context, path Path, baseURL string, chain []string, depth int) error {
+	includeURL, err := r.graft(ctx, path, baseURL, chain, depth)
+	if err != nil {
+		return err
+	}
+
+	return r.resolve(ctx, path, includeURL, append(slices.Clip(chain), NormalizeURL(includeURL)), depth+1)
+}
+
+// graft grafts the body of the document included by the inclusion Outline
+// located at path, and returns the resolved URL of the included document.
+func (r *includeResolver) graft(ctx context.Context, path Path, baseURL string, chain []string, depth int) (string, error) {
+	outline, _ := r.document.Get(path)
+
+	includeURL, err := resolveReference(baseURL, outline.Url)
+	if err != nil {
+		return "", &IncludeError{Path: path, URL: outline.Url, Err: err}
+	}
+
+	if slices.Contains(chain, NormalizeURL(includeURL)) {
+		return "", &IncludeError{Path: path, URL: includeURL, Err: ErrIncludeCycle}
+	}
+
+	if depth >= r.maxDepth {
+		return "", &IncludeError{Path: path, URL: includeURL, Err: ErrIncludeDepth}
+	}
+
+	included, err := r.fetch(ctx, includeURL)
+	if err != nil {
+		return "", &IncludeError{Path: path, URL: includeURL, Err: err}
+	}
+
+	for _, includedOutline := range included.All() {
+		includedOutline.Source = includeURL
+	}
+
+	if err := r.document.Insert(append(path.clone(), 0), included.Body.Outlines...); err != nil {
+		return "", err
+	}
+
+	return includeURL, nil
+}
+
+func (r *includeResolver) fetch(ctx context.Context, rawURL string) (*Document, error) {
+	rc, err := r.fetcher.Fetch(ctx, rawURL)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	return unmarshal(rc)
+}
+
+// outlines returns the subordinated Outlines of the Outline located at parent,
+// or the top-level Outlines if parent is empty.
+func (r *includeResolver) outlines(parent Path) []Outline {
+	if len(parent) == 0 {
+		return r.document.Body.Outlines
+	}
+
+	outline, _ := r.document.Get(parent)
+
+	return outline.Outlines
+}
+
+// resolveReference resolves a URL reference relative to a base URL.
+func resolveReference(baseURL, ref string) (string, error) {
+	refURL, err := url.Parse(ref)
+	if err != nil {
+		return "", err
+	}
+
+	if baseURL == "" {
+		return refURL.String(), nil
+	}
+
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return "", err
+	}
+
+	// Relative file paths are resolved as such, instead of as absolute URL paths
+	if !base.IsAbs() && base.Host == "" && !strings.HasPrefix(base.Path, "/") &&
+		!refURL.IsAbs() && refURL.Host == "" && !strings.HasPrefix(refURL.Path, "/") {
+		return path.Join(path.Dir(base.Path), refURL.Path), nil
+	}
+
+	return base.ResolveReference(refURL).String(), nil
+}
@@ -0,0 +1,640 @@
+// Copyright (c) VirtualTam
+// SPDX-License-Identifier: MIT
+
+package opml
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// newIncludeServer returns a test HTTP server serving OPML documents by path.
+func newIncludeServer(t *testing.T, documents map[string]string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		document, ok := documents[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/x-opml")
+		fmt.Fprint(w, document)
+	}))
+
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// includeDocument returns the OPML representation of a document with the given body.
+func includeDocument(body string) string {
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<opml version="2.0"><head><title>Included</title></head><body>` + body + `</body></opml>`
+}
+
+func TestDocumentResolveIncludesHTTP(t *testing.T) {
+	server := newIncludeServer(t, map[string]string{
+		"/programming.opml": includeDocument(`
+			<outline type="rss" text="Go" xmlUrl="https://go.dev/blog/feed.atom"/>
+			<outline text="Elixir" type="include" url="elixir/elixir.opml"/>`),
+		"/elixir/elixir.opml": includeDocument(`
+			<outline type="rss" text="Elixir Lang" xmlUrl="https://feeds.feedburner.com/ElixirLang"/>`),
+	})
+
+	document := &Document{
+		Version: Version2,
+		Head: Head{
+			ExpansionState: []int{2},
+		},
+		Body: Body{
+			Outlines: []Outline{
+				{Text: "Programming", Type: OutlineTypeInclusion, Url: server.URL + "/programming.opml"},
+				{
+					Text: "News",
+					Outlines: []Outline{
+						{Text: "Lobsters", Type: OutlineTypeSubscription, XmlUrl: "https://lobste.rs/rss"},
+					},
+				},
+			},
+		},
+	}
+
+	if err := document.ResolveIncludes(context.Background(), HTTPFetcher{Client: server.Client()}); err != nil {
+		t.Fatalf("want no error, got %q", err)
+	}
+
+	want := Document{
+		Version: Version2,
+		Body: Body{
+			Outlines: []Outline{
+				{
+					Text: "Programming",
+					Type: OutlineTypeInclusion,
+					Url:  server.URL + "/programming.opml",
+					Outlines: []Outline{
+						{Text: "Go", Type: OutlineTypeSubscription, XmlUrl: "https://go.dev/blog/feed.atom"},
+						{
+							Text: "Elixir",
+							Type: OutlineTypeInclusion,
+							Url:  "elixir/elixir.opml",
+							Outlines: []Outline{
+								{Text: "Elixir Lang", Type: OutlineTypeSubscription, XmlUrl: "https://feeds.feedburner.com/ElixirLang"},
+							},
+						},
+					},
+				},
+				{
+					Text: "News",
+					Outlines: []Outline{
+						{Text: "Lobsters", Type: OutlineTypeSubscription, XmlUrl: "https://lobste.rs/rss"},
+					},
+				},
+			},
+		},
+	}
+
+	AssertDocumentsEqual(t, *document, want)
+
+	wantExpansionState := []int{5}
+	if !slices.Equal(document.Head.ExpansionState, wantExpansionState) {
+		t.Errorf("want ExpansionState %v, got %v", wantExpansionState, document.Head.ExpansionState)
+	}
+
+	var gotSources []string
+	for _, outline := range document.All() {
+		gotSources = append(gotSources, outline.Source)
+	}
+
+	wantSources := []string{
+		"",
+		server.URL + "/programming.opml",
+		server.URL + "/programming.opml",
+		server.URL + "/elixir/elixir.opml",
+		"",
+		"",
+	}
+
+	if !slices.Equal(gotSources, wantSources) {
+		t.Errorf("want Sources %q, got %q", wantSources, gotSources)
+	}
+
+	// Resolved inclusion Outlines are left unchanged
+	if err := document.ResolveIncludes(context.Background(), HTTPFetcher{Client: server.Client()}); err != nil {
+		t.Fatalf("want no error, got %q", err)
+	}
+
+	AssertDocumentsEqual(t, *document, want)
+}
+
+func TestDocumentResolveIncludesFile(t *testing.T) {
+	document, err := UnmarshalFile("testdata/include/root.opml")
+	if err != nil {
+		t.Fatalf("want no error, got %q", err)
+	}
+
+	options := IncludeOptions{
+		Fetcher: FileFetcher{},
+		BaseURL: "testdata/include/root.opml",
+	}
+
+	if err := document.ResolveIncludesWithOptions(context.Background(), options); err != nil {
+		t.Fatalf("want no error, got %q", err)
+	}
+
+	got := collectOutlines(document.All())
+
+	want := []string{
+		"0 Programming",
+		"0.0 Go",
+		"0.1 Elixir",
+		"0.1.0 Elixir Lang",
+		"1 News",
+		"1.0 Lobsters",
+	}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("want Outlines %q, got %q", want, got)
+	}
+
+	elixirLang, _ := document.Get(Path{0, 1, 0})
+
+	if wantSource := "testdata/include/elixir/elixir.opml"; elixirLang.Source != wantSource {
+		t.Errorf("want Source %q, got %q", wantSource, elixirLang.Source)
+	}
+
+	wantExpansionState := []int{1, 5}
+	if !slices.Equal(document.Head.ExpansionState, wantExpansionState) {
+		t.Errorf("want ExpansionState %v, got %v", wantExpansionState, document.Head.ExpansionState)
+	}
+}
+
+func TestDocumentResolveIncludesFileCycle(t *testing.T) {
+	document, err := UnmarshalFile("testdata/include/cycle.opml")
+	if err != nil {
+		t.Fatalf("want no error, got %q", err)
+	}
+
+	options := IncludeOptions{
+		Fetcher: FileFetcher{},
+		BaseURL: "testdata/include/cycle.opml",
+	}
+
+	err = document.ResolveIncludesWithOptions(context.Background(), options)
+	if !errors.Is(err, ErrIncludeCycle) {
+		t.Errorf("want error %q, got %q", ErrIncludeCycle, err)
+	}
+}
+
+func TestDocumentResolveIncludesError(t *testing.T) {
+	server := newIncludeServer(t, map[string]string{
+		"/self.opml":    includeDocument(`<outline text="Self" type="include" url="self.opml"/>`),
+		"/a.opml":       includeDocument(`<outline text="B" type="include" url="b.opml"/>`),
+		"/b.opml":       includeDocument(`<outline text="A" type="include" url="/a.opml"/>`),
+		"/nested.opml":  includeDocument(`<outline text="Leaf" type="include" url="leaf.opml"/>`),
+		"/leaf.opml":    includeDocument(`<outline text="Leaf"/>`),
+		"/invalid.opml": `<opml version="2.0"><body><outline text="Unclosed"></body></opml>`,
+		"/large.opml":   includeDocument(strings.Repeat(`<outline text="Large"/>`, 16)),
+	})
+
+	cases := []struct {
+		tname    string
+		url      string
+		maxDepth int
+		maxSize  int64
+		wantErr  error
+		wantPath Path
+		wantURL  string
+	}{
+		{
+			tname:    "self inclusion",
+			url:      "/self.opml",
+			wantErr:  ErrIncludeCycle,
+			wantPath: Path{0, 0},
+			wantURL:  server.URL + "/self.opml",
+		},
+		{
+			tname:    "inclusion cycle",
+			url:      "/a.opml",
+			wantErr:  ErrIncludeCycle,
+			wantPath: Path{0, 0, 0},
+			wantURL:  server.URL + "/a.opml",
+		},
+		{
+			tname:    "maximum depth",
+			url:      "/nested.opml",
+			maxDepth: 1,
+			wantErr:  ErrIncludeDepth,
+			wantPath: Path{0, 0},
+			wantURL:  server.URL + "/leaf.opml",
+		},
+		{
+			tname:    "not found",
+			url:      "/missing.opml",
+			wantErr:  ErrUnexpectedStatus,
+			wantPath: Path{0},
+			wantURL:  server.URL + "/missing.opml",
+		},
+		{
+			tname:    "too large",
+			url:      "/large.opml",
+			maxSize:  128,
+			wantErr:  ErrFetchTooLarge,
+			wantPath: Path{0},
+			wantURL:  server.URL + "/large.opml",
+		},
+		{
+			tname:    "invalid document",
+			url:      "invalid.opml",
+			wantPath: Path{0},
+			wantURL:  server.URL + "/invalid.opml",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.tname, func(t *testing.T) {
+			document := &Document{
+				Version: Version2,
+				Body: Body{
+					Outlines: []Outline{
+						{Text: "Included", Type: OutlineTypeInclusion, Url: tc.url},
+					},
+				},
+			}
+
+			options := IncludeOptions{
+				Fetcher:  HTTPFetcher{Client: server.Client(), MaxSize: tc.maxSize},
+				BaseURL:  server.URL + "/root.opml",
+				MaxDepth: tc.maxDepth,
+			}
+
+			err := document.ResolveIncludesWithOptions(context.Background(), options)
+			if err == nil {
+				t.Fatal("want error, got none")
+			}
+
+			if tc.wantErr != nil && !errors.Is(err, tc.wantErr) {
+				t.Errorf("want error %q, got %q", tc.wantErr, err)
+			}
+
+			var includeErr *IncludeError
+			if !errors.As(err, &includeErr) {
+				t.Fatalf("want IncludeError, got %q", err)
+			}
+
+			if !slices.Equal(includeErr.Path, tc.wantPath) {
+				t.Errorf("want Path %s, got %s", tc.wantPath, includeErr.Path)
+			}
+			if includeErr.URL != tc.wantURL {
+				t.Errorf("want URL %q, got %q", tc.wantURL, includeErr.URL)
+			}
+		})
+	}
+}
+
+func TestHTTPFetcherMaxSize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Flushing before writing the body omits the Content-Length header
+		w.(http.Flusher).Flush()
+		fmt.Fprint(w, strings.Repeat("a", 16))
+	}))
+	t.Cleanup(server.Close)
+
+	cases := []struct {
+		tname   string
+		maxSize int64
+		wantErr error
+	}{
+		{
+			tname:   "within limit",
+			maxSize: 16,
+		},
+		{
+			tname:   "over limit",
+			maxSize: 15,
+			wantErr: ErrFetchTooLarge,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.tname, func(t *testing.T) {
+			fetcher := HTTPFetcher{Client: server.Client(), MaxSize: tc.maxSize}
+
+			rc, err := fetcher.Fetch(context.Background(), server.URL)
+			if err != nil {
+				t.Fatalf("want no error, got %q", err)
+			}
+			defer rc.Close()
+
+			data, err := io.ReadAll(rc)
+
+			if tc.wantErr == nil {
+				if err != nil {
+					t.Fatalf("want no error, got %q", err)
+				}
+			} else if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("want error %q, got %q", tc.wantErr, err)
+			}
+
+			if int64(len(data)) > tc.maxSize {
+				t.Errorf("want at most %d bytes, got %d", tc.maxSize, len(data))
+			}
+
+			// Reading past the end keeps returning the same error
+			wantErr := tc.wantErr
+			if wantErr == nil {
+				wantErr = io.EOF
+			}
+
+			n, err := rc.Read(make([]byte, 8))
+			if n != 0 {
+				t.Errorf("want 0 bytes read again, got %d", n)
+			}
+			if !errors.Is(err, wantErr) {
+				t.Errorf("want error %q when reading again, got %q", wantErr, err)
+			}
+		})
+	}
+}
+
+func TestDocumentResolveIncludesFetcherFunc(t *testing.T) {
+	document := &Document{
+		Version: Version2,
+		Body: Body{
+			Outlines: []Outline{
+				{Text: "Included", Type: OutlineTypeInclusion, Url: "https://example.org/included.opml"},
+			},
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	fetcher := FetcherFunc(func(ctx context.Context, url string) (io.ReadCloser, error) {
+		return FileFetcher{}.Fetch(ctx, "testdata/include/root.opml")
+	})
+
+	err := document.ResolveIncludes(ctx, fetcher)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("want error %q, got %q", context.Canceled, err)
+	}
+}
+
+func TestOutlineIsInclusion(t *testing.T) {
+	cases := []struct {
+		tname   string
+		outline Outline
+		want    bool
+	}{
+		{
+			tname:   "inclusion",
+			outline: Outline{Type: OutlineTypeInclusion, Url: "http://hosting.opml.org/dave/mySites.opml"},
+			want:    true,
+		},
+		{
+			tname:   "link to an OPML document",
+			outline: Outline{Type: OutlineTypeLink, Url: "http://hosting.opml.org/dave/mySites.opml"},
+			want:    true,
+		},
+		{
+			tname:   "link to an OPML document with a query",
+			outline: Outline{Type: OutlineTypeLink, Url: "http://example.org/Directory.OPML?page=2"},
+			want:    true,
+		},
+		{
+			tname:   "link to a web page",
+			outline: Outline{Type: OutlineTypeLink, Url: "http://www.opml.org/spec2"},
+			want:    false,
+		},
+		{
+			tname:   "subscription",
+			outline: Outline{Type: OutlineTypeSubscription, XmlUrl: "http://scripting.com/rss.xml"},
+			want:    false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.tname, func(t *testing.T) {
+			if got := tc.outline.IsInclusion(); got != tc.want {
+				t.Errorf("want %t, got %t", tc.want, got)
+			}
+		})
+	}
+}
+
+// newDirectoryFetcher returns a Fetcher serving the documents linked from the
+// spec directory, and counting the requests made for each URL.
+func newDirectoryFetcher(requests map[string]int) Fetcher {
+	documents := map[string]string{
+		"http://hosting.opml.org/dave/mySites.opml": includeDocument(`
+			<outline text="Scripting News" type="rss" xmlUrl="http://scripting.com/rss.xml"/>
+			<outline text="More sites" type="link" url="more/sites.opml"/>`),
+		"http://hosting.opml.org/dave/more/sites.opml": includeDocument(`
+			<outline text="DaveNet" type="rss" xmlUrl="http://davenet.opml.org/rss.xml"/>`),
+	}
+
+	return FetcherFunc(func(ctx context.Context, url string) (io.ReadCloser, error) {
+		requests[url]++
+
+		document, ok := documents[url]
+		if !ok {
+			return nil, fmt.Errorf("%w: 404 Not Found", ErrUnexpectedStatus)
+		}
+
+		return io.NopCloser(strings.NewReader(document)), nil
+	})
+}
+
+func TestDocumentExpand(t *testing.T) {
+	requests := make(map[string]int)
+	fetcher := NewCachingFetcher(newDirectoryFetcher(requests))
+
+	document, err := UnmarshalFile("testdata/spec/unmarshal/directory.opml")
+	if err != nil {
+		t.Fatalf("want no error, got %q", err)
+	}
+
+	options := IncludeOptions{Fetcher: fetcher}
+
+	if err := document.Expand(context.Background(), Path{0}, options); err != nil {
+		t.Fatalf("want no error, got %q", err)
+	}
+
+	// Included links are not expanded until requested
+	mySites, _ := document.Get(Path{0})
+
+	got := collectOutlines(mySites.Descendants())
+	want := []string{
+		"0 Scripting News",
+		"1 More sites",
+	}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("want Outlines %q, got %q", want, got)
+	}
+
+	if err := document.Expand(context.Background(), Path{0, 1}, options); err != nil {
+		t.Fatalf("want no error, got %q", err)
+	}
+
+	daveNet, ok := document.Get(Path{0, 1, 0})
+	if !ok {
+		t.Fatal("want expanded Outline, got none")
+	}
+
+	if wantSource := "http://hosting.opml.org/dave/more/sites.opml"; daveNet.Source != wantSource {
+		t.Errorf("want Source %q, got %q", wantSource, daveNet.Source)
+	}
+
+	// Expanded Outlines are left unchanged
+	if err := document.Expand(context.Background(), Path{0}, options); err != nil {
+		t.Fatalf("want no error, got %q", err)
+	}
+
+	if n := len(mySites.Outlines); n != 2 {
+		t.Errorf("want 2 Outlines, got %d", n)
+	}
+
+	// Documents are fetched once
+	other, err := UnmarshalFile("testdata/spec/unmarshal/directory.opml")
+	if err != nil {
+		t.Fatalf("want no error, got %q", err)
+	}
+
+	if err := other.Expand(context.Background(), Path{0}, options); err != nil {
+		t.Fatalf("want no error, got %q", err)
+	}
+
+	if n := requests["http://hosting.opml.org/dave/mySites.opml"]; n != 1 {
+		t.Errorf("want 1 request, got %d", n)
+	}
+
+	fetcher.Forget("http://hosting.opml.org/dave/mySites.opml")
+
+	if _, err := fetcher.Fetch(context.Background(), "http://hosting.opml.org/dave/mySites.opml"); err != nil {
+		t.Fatalf("want no error, got %q", err)
+	}
+
+	if n := requests["http://hosting.opml.org/dave/mySites.opml"]; n != 2 {
+		t.Errorf("want 2 requests, got %d", n)
+	}
+}
+
+func TestDocumentExpandError(t *testing.T) {
+	cases := []struct {
+		tname   string
+		path    Path
+		wantErr error
+	}{
+		{
+			tname:   "not found",
+			path:    Path{42},
+			wantErr: ErrOutlineNotFound,
+		},
+		{
+			tname:   "not an inclusion",
+			path:    Path{1},
+			wantErr: ErrNotInclusion,
+		},
+		{
+			tname:   "fetching error",
+			path:    Path{2},
+			wantErr: ErrUnexpectedStatus,
+		},
+		{
+			tname:   "inclusion cycle",
+			path:    Path{3},
+			wantErr: ErrIncludeCycle,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.tname, func(t *testing.T) {
+			document := &Document{
+				Version: Version2,
+				Body: Body{
+					Outlines: []Outline{
+						{Text: "My sites", Type: OutlineTypeLink, Url: "http://hosting.opml.org/dave/mySites.opml"},
+						{Text: "OPML 2.0", Type: OutlineTypeLink, Url: "http://www.opml.org/spec2"},
+						{Text: "Missing", Type: OutlineTypeLink, Url: "http://example.org/missing.opml"},
+						{Text: "Directory", Type: OutlineTypeLink, Url: "directory.opml"},
+					},
+				},
+			}
+
+			options := IncludeOptions{
+				Fetcher: newDirectoryFetcher(make(map[string]int)),
+				BaseURL: "http://hosting.opml.org/dave/directory.opml",
+			}
+
+			err := document.Expand(context.Background(), tc.path, options)
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("want error %q, got %q", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestDocumentResolveIncludesFollowLinks(t *testing.T) {
+	cases := []struct {
+		tname       string
+		followLinks bool
+		want        []string
+	}{
+		{
+			tname: "inclusions only",
+			want: []string{
+				"0 My sites",
+				"1 OPML 2.0",
+			},
+		},
+		{
+			tname:       "follow links",
+			followLinks: true,
+			want: []string{
+				"0 My sites",
+				"0.0 Scripting News",
+				"0.1 More sites",
+				"0.1.0 DaveNet",
+				"1 OPML 2.0",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.tname, func(t *testing.T) {
+			document := &Document{
+				Version: Version2,
+				Body: Body{
+					Outlines: []Outline{
+						{Text: "My sites", Type: OutlineTypeLink, Url: "http://hosting.opml.org/dave/mySites.opml"},
+						{Text: "OPML 2.0", Type: OutlineTypeLink, Url: "http://www.opml.org/spec2"},
+					},
+				},
+			}
+
+			options := IncludeOptions{
+				Fetcher:     newDirectoryFetcher(make(map[string]int)),
+				FollowLinks: tc.followLinks,
+			}
+
+			if err := document.ResolveIncludesWithOptions(context.Background(), options); err != nil {
+				t.Fatalf("want no error, got %q", err)
+			}
+
+			got := collectOutlines(document.All())
+
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("want Outlines %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
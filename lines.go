@@ -0,0 +1,233 @@
+// Copyright (c) VirtualTam
+// SPDX-License-Identifier: MIT
+
+package opml
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Lines returns the Paths of the Outlines of the Document, indexed by line number.
+//
+// Lines are numbered from 1, in document order, as referred to by the Head
+// ExpansionState and VertScrollState; the Path at index 0 is nil.
+func (d *Document) Lines() []Path {
+	lines := []Path{nil}
+
+	for path := range d.All() {
+		lines = append(lines, path)
+	}
+
+	return lines
+}
+
+// Line returns the line number of the Outline located by the given Path.
+func (d *Document) Line(path Path) (int, bool) {
+	if _, ok := d.Get(path); !ok {
+		return 0, false
+	}
+
+	return lineAt(d.Body.Outlines, path), true
+}
+
+// PathAt returns the Path of the Outline at the given line number.
+func (d *Document) PathAt(line int) (Path, bool) {
+	if line < 1 {
+		return nil, false
+	}
+
+	current := 0
+
+	for path := range d.All() {
+		current++
+
+		if current == line {
+			return path, true
+		}
+	}
+
+	return nil, false
+}
+
+// IsExpanded returns whether the Outline located by the given Path is listed
+// in the Head ExpansionState.
+func (d *Document) IsExpanded(path Path) bool {
+	line, ok := d.Line(path)
+	if !ok {
+		return false
+	}
+
+	return slices.Contains(d.Head.ExpansionState, line)
+}
+
+// SetExpanded adds or removes the Outline located by the given Path to or from
+// the Head ExpansionState, that is kept in ascending order.
+func (d *Document) SetExpanded(path Path, expanded bool) error {
+	line, ok := d.Line(path)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrOutlineNotFound, path)
+	}
+
+	switch {
+	case expanded && !slices.Contains(d.Head.ExpansionState, line):
+		d.Head.ExpansionState = append(d.Head.ExpansionState, line)
+		slices.Sort(d.Head.ExpansionState)
+
+	case !expanded:
+		d.Head.ExpansionState = slices.DeleteFunc(d.Head.ExpansionState, func(state int) bool {
+			return state == line
+		})
+		if len(d.Head.ExpansionState) == 0 {
+			d.Head.ExpansionState = nil
+		}
+	}
+
+	return nil
+}
+
+// ExpandedPaths returns the Paths of the Outlines listed in the Head
+// ExpansionState, in document order.
+//
+// Line numbers that do not refer to an Outline are ignored.
+func (d *Document) ExpandedPaths() []Path {
+	var paths []Path
+
+	line := 0
+
+	for path := range d.All() {
+		line++
+
+		if slices.Contains(d.Head.ExpansionState, line) {
+			paths = append(paths, path)
+		}
+	}
+
+	return paths
+}
+
+// SetExpandedPaths replaces the Head ExpansionState with the line numbers of
+// the Outlines located by the given Paths, in ascending order.
+func (d *Document) SetExpandedPaths(paths ...Path) error {
+	var expansionState []int
+
+	for _, path := range paths {
+		line, ok := d.Line(path)
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrOutlineNotFound, path)
+		}
+
+		expansionState = append(expansionState, line)
+	}
+
+	slices.Sort(expansionState)
+
+	d.Head.ExpansionState = slices.Compact(expansionState)
+
+	return nil
+}
+
+// countLines returns the number of lines occupied by outlines and their
+// subordinated Outlines.
+func countLines(outlines []Outline) int {
+	count := len(outlines)
+
+	for _, outline := range outlines {
+		count += countLines(outline.Outlines)
+	}
+
+	return count
+}
+
+// lineAt returns the line number of the given position within outlines, that
+// may be one past the last Outline of its parent.
+//
+// Lines are numbered from 1, in document order.
+func lineAt(outlines []Outline, path Path) int {
+	line := 1
+
+	for depth, index := range path {
+		line += countLines(outlines[:index])
+
+		if depth < len(path)-1 {
+			line++
+			outlines = outlines[index].Outlines
+		}
+	}
+
+	return line
+}
+
+// lineStates holds the Head line numbers referring to a range of lines, as
+// offsets from the start of the range.
+type lineStates struct {
+	expansionState  []int
+	vertScrollState int
+}
+
+// removeLines removes n lines starting at line from the line numbers of the
+// Head, and returns the line numbers that referred to the removed range.
+func (h *Head) removeLines(line, n int) lineStates {
+	removed := lineStates{vertScrollState: -1}
+
+	var expansionState []int
+
+	for _, state := range h.ExpansionState {
+		switch {
+		case state < line:
+			expansionState = append(expansionState, state)
+		case state < line+n:
+			removed.expansionState = append(removed.expansionState, state-line)
+		default:
+			expansionState = append(expansionState, state-n)
+		}
+	}
+
+	h.ExpansionState = expansionState
+
+	switch {
+	case h.VertScrollState < line:
+	case h.VertScrollState < line+n:
+		removed.vertScrollState = h.VertScrollState - line
+		h.VertScrollState = line
+	default:
+		h.VertScrollState -= n
+	}
+
+	return removed
+}
+
+// insertLines inserts n lines starting at line in the line numbers of the Head,
+// restoring the line numbers that referred to the inserted range, if any.
+func (h *Head) insertLines(line, n int, states *lineStates) {
+	for i, state := range h.ExpansionState {
+		if state >= line {
+			h.ExpansionState[i] = state + n
+		}
+	}
+
+	if h.VertScrollState >= line {
+		h.VertScrollState += n
+	}
+
+	if states == nil {
+		return
+	}
+
+	for _, offset := range states.expansionState {
+		h.ExpansionState = append(h.ExpansionState, line+offset)
+	}
+	slices.Sort(h.ExpansionState)
+
+	if states.vertScrollState >= 0 {
+		h.VertScrollState = line + states.vertScrollState
+	}
+}
+
+// clampVertScrollState ensures the vertical scroll state refers to one of the
+// given number of lines.
+func (h *Head) clampVertScrollState(nLines int) {
+	if h.VertScrollState > nLines {
+		h.VertScrollState = nLines
+	}
+}
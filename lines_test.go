@@ -0,0 +1,199 @@
+// Copyright (c) VirtualTam
+// SPDX-License-Identifier: MIT
+
+package opml
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestDocumentLines(t *testing.T) {
+	document := cloneDocument(&walkDocument)
+
+	lines := document.Lines()
+
+	want := []string{"Programming", "Elixir", "Elixir Lang", "Go", "News", "Lobsters", "Unsorted"}
+
+	if len(lines) != len(want)+1 {
+		t.Fatalf("want %d lines, got %d", len(want)+1, len(lines))
+	}
+
+	if lines[0] != nil {
+		t.Errorf("want nil Path at index 0, got %v", lines[0])
+	}
+
+	for index, wantText := range want {
+		line := index + 1
+		path := lines[line]
+
+		outline, ok := document.Get(path)
+		if !ok {
+			t.Fatalf("line %d: want Outline at Path %s, got none", line, path)
+		}
+
+		if outline.Text != wantText {
+			t.Errorf("line %d: want Text %q, got %q", line, wantText, outline.Text)
+		}
+
+		gotLine, ok := document.Line(path)
+		if !ok {
+			t.Fatalf("line %d: want line number for Path %s, got none", line, path)
+		}
+		if gotLine != line {
+			t.Errorf("Path %s: want line %d, got %d", path, line, gotLine)
+		}
+
+		gotPath, ok := document.PathAt(line)
+		if !ok {
+			t.Fatalf("line %d: want Path, got none", line)
+		}
+		if !slices.Equal(gotPath, path) {
+			t.Errorf("line %d: want Path %s, got %s", line, path, gotPath)
+		}
+	}
+
+	if _, ok := document.Line(Path{3}); ok {
+		t.Errorf("want no line number for a missing Outline")
+	}
+
+	for _, line := range []int{0, 8} {
+		if _, ok := document.PathAt(line); ok {
+			t.Errorf("line %d: want no Path", line)
+		}
+	}
+}
+
+func TestDocumentExpandedPaths(t *testing.T) {
+	var got []string
+
+	for _, path := range specDocumentPlacesLived.ExpandedPaths() {
+		outline, ok := specDocumentPlacesLived.Get(path)
+		if !ok {
+			t.Fatalf("want Outline at Path %s, got none", path)
+		}
+
+		got = append(got, outline.Text)
+	}
+
+	want := []string{"Places I've lived", "Boston", "Bay Area", "New Orleans", "Wisconsin", "Florida"}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestDocumentIsExpanded(t *testing.T) {
+	document := cloneDocument(&walkDocument)
+
+	cases := []struct {
+		path Path
+		want bool
+	}{
+		{Path{0}, true},
+		{Path{0, 0}, true},
+		{Path{0, 0, 0}, false},
+		{Path{0, 1}, false},
+		{Path{1}, true},
+		{Path{1, 0}, false},
+		{Path{2}, false},
+		{Path{3}, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.path.String(), func(t *testing.T) {
+			if got := document.IsExpanded(tc.path); got != tc.want {
+				t.Errorf("want %t, got %t", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestDocumentSetExpanded(t *testing.T) {
+	cases := []struct {
+		tname              string
+		path               Path
+		expanded           bool
+		wantExpansionState []int
+	}{
+		{
+			tname:              "expand",
+			path:               Path{0, 1},
+			expanded:           true,
+			wantExpansionState: []int{1, 2, 4, 5},
+		},
+		{
+			tname:              "expand an expanded outline",
+			path:               Path{1},
+			expanded:           true,
+			wantExpansionState: []int{1, 2, 5},
+		},
+		{
+			tname:              "collapse",
+			path:               Path{0, 0},
+			expanded:           false,
+			wantExpansionState: []int{1, 5},
+		},
+		{
+			tname:              "collapse a collapsed outline",
+			path:               Path{2},
+			expanded:           false,
+			wantExpansionState: []int{1, 2, 5},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.tname, func(t *testing.T) {
+			document := cloneDocument(&walkDocument)
+
+			if err := document.SetExpanded(tc.path, tc.expanded); err != nil {
+				t.Fatalf("want no error, got %q", err)
+			}
+
+			if !slices.Equal(document.Head.ExpansionState, tc.wantExpansionState) {
+				t.Errorf("want ExpansionState %v, got %v", tc.wantExpansionState, document.Head.ExpansionState)
+			}
+
+			if got := document.IsExpanded(tc.path); got != tc.expanded {
+				t.Errorf("want IsExpanded %t, got %t", tc.expanded, got)
+			}
+		})
+	}
+
+	t.Run("missing outline", func(t *testing.T) {
+		document := cloneDocument(&walkDocument)
+
+		err := document.SetExpanded(Path{0, 2}, true)
+		if !errors.Is(err, ErrOutlineNotFound) {
+			t.Fatalf("want error %q, got %q", ErrOutlineNotFound, err)
+		}
+	})
+}
+
+func TestDocumentSetExpandedPaths(t *testing.T) {
+	document := cloneDocument(&walkDocument)
+
+	if err := document.SetExpandedPaths(Path{1}, Path{0, 1}, Path{1}); err != nil {
+		t.Fatalf("want no error, got %q", err)
+	}
+
+	want := []int{4, 5}
+
+	if !slices.Equal(document.Head.ExpansionState, want) {
+		t.Errorf("want ExpansionState %v, got %v", want, document.Head.ExpansionState)
+	}
+
+	if err := document.SetExpandedPaths(); err != nil {
+		t.Fatalf("want no error, got %q", err)
+	}
+
+	if document.Head.ExpansionState != nil {
+		t.Errorf("want empty ExpansionState, got %v", document.Head.ExpansionState)
+	}
+
+	err := document.SetExpandedPaths(Path{0}, Path{5})
+	if !errors.Is(err, ErrOutlineNotFound) {
+		t.Fatalf("want error %q, got %q", ErrOutlineNotFound, err)
+	}
+}
@@ -4,28 +4,26 @@
 package opml
 
 import (
-	"bufio"
 	"bytes"
-	"encoding/xml"
 	"io"
 	"os"
 	"strings"
-
-	"golang.org/x/net/html/charset"
 )
 
 // Marshal returns the XML encoding of a Document.
 func Marshal(d *Document) ([]byte, error) {
+	return MarshalWithOptions(d, MarshalOptions{})
+}
+
+// MarshalWithOptions returns the XML encoding of a Document, using the given options.
+func MarshalWithOptions(d *Document, options MarshalOptions) ([]byte, error) {
 	var buf bytes.Buffer
-	writer := bufio.NewWriter(&buf)
-	_, err := writer.WriteString(xml.Header)
+
+	encoder, err := NewEncoderWithOptions(&buf, options)
 	if err != nil {
 		return []byte{}, err
 	}
 
-	encoder := xml.NewEncoder(writer)
-	encoder.Indent("", "  ")
-
 	if err := encoder.Encode(d); err != nil {
 		return []byte{}, err
 	}
@@ -40,6 +38,15 @@ func Unmarshal(buf []byte) (*Document, error) {
 	return unmarshal(r)
 }
 
+// UnmarshalWithOptions unmarshals a []byte representation of an OPML file using
+// the given options, and returns the corresponding Document.
+//
+// When decoding leniently, invalid field values are returned as warnings.
+func UnmarshalWithOptions(buf []byte, options UnmarshalOptions) (*Document, []*DecodeError, error) {
+	r := bytes.NewReader(buf)
+	return unmarshalWithOptions(r, options)
+}
+
 // UnmarshalFile unmarshals an OPML file and returns the corresponding Document.
 func UnmarshalFile(filePath string) (*Document, error) {
 	file, err := os.Open(filePath)
@@ -51,6 +58,20 @@ func UnmarshalFile(filePath string) (*Document, error) {
 	return unmarshal(file)
 }
 
+// UnmarshalFileWithOptions unmarshals an OPML file using the given options, and
+// returns the corresponding Document.
+//
+// When decoding leniently, invalid field values are returned as warnings.
+func UnmarshalFileWithOptions(filePath string, options UnmarshalOptions) (*Document, []*DecodeError, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return &Document{}, nil, err
+	}
+	defer file.Close()
+
+	return unmarshalWithOptions(file, options)
+}
+
 // Unmarshal unmarshals a string representation of an OPML file and returns the
 // corresponding Document.
 func UnmarshalString(data string) (*Document, error) {
@@ -58,15 +79,33 @@ func UnmarshalString(data string) (*Document, error) {
 	return unmarshal(r)
 }
 
-func unmarshal(r io.Reader) (*Document, error) {
-	decoder := xml.NewDecoder(r)
-	decoder.CharsetReader = charset.NewReaderLabel
+// UnmarshalStringWithOptions unmarshals a string representation of an OPML file
+// using the given options, and returns the corresponding Document.
+//
+// When decoding leniently, invalid field values are returned as warnings.
+func UnmarshalStringWithOptions(data string, options UnmarshalOptions) (*Document, []*DecodeError, error) {
+	r := strings.NewReader(data)
+	return unmarshalWithOptions(r, options)
+}
 
-	document := &Document{}
+func unmarshal(r io.Reader) (*Document, error) {
+	decoder := NewDecoder(r)
 
-	if err := decoder.Decode(document); err != nil {
+	document, err := decoder.decodeDocument()
+	if err != nil {
 		return &Document{}, err
 	}
 
 	return document, nil
 }
+
+func unmarshalWithOptions(r io.Reader, options UnmarshalOptions) (*Document, []*DecodeError, error) {
+	decoder := NewDecoderWithOptions(r, options)
+
+	document, err := decoder.decodeDocument()
+	if err != nil {
+		return &Document{}, decoder.Warnings(), err
+	}
+
+	return document, decoder.Warnings(), nil
+}
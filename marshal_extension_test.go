@@ -0,0 +1,287 @@
+// Copyright (c) VirtualTam
+// SPDX-License-Identifier: MIT
+
+package opml
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var (
+	extensionDocumentAttributes = Document{
+		XMLName: xml.Name{
+			Local: "opml",
+		},
+		Version: Version2,
+		Head: Head{
+			Title: "Subscriptions with extra attributes",
+		},
+		Body: Body{
+			Outlines: []Outline{
+				{
+					Text:  "Programming",
+					Title: "Programming",
+					Attributes: []xml.Attr{
+						{Name: xml.Name{Local: "sortOrder"}, Value: "manual"},
+					},
+					Outlines: []Outline{
+						{
+							Text:   "Git Rev News",
+							Title:  "Git Rev News",
+							Type:   OutlineTypeSubscription,
+							XmlUrl: "https://git.github.io/feed.xml",
+							Attributes: []xml.Attr{
+								{Name: xml.Name{Local: "rssfr-favicon"}, Value: "https://git.github.io/favicon.ico"},
+								{Name: xml.Name{Local: "rssfr-useCustomTitle"}, Value: "false"},
+							},
+						},
+						{
+							Text:   "The Go Programming Language Blog",
+							Title:  "The Go Programming Language Blog",
+							Type:   OutlineTypeSubscription,
+							XmlUrl: "https://go.dev/blog/feed.atom",
+							Attributes: []xml.Attr{
+								{Name: xml.Name{Local: "unread"}, Value: "12"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	extensionDocumentNamespaces = Document{
+		XMLName: xml.Name{
+			Local: "opml",
+		},
+		Version: Version2,
+		Namespaces: []Namespace{
+			{Prefix: "sync", URI: "https://example.org/ns/sync"},
+			{Prefix: "health", URI: "https://example.org/ns/feed-health"},
+		},
+		Head: Head{
+			Title: "Subscriptions with namespaced extensions",
+			Elements: []Element{
+				{Name: xml.Name{Space: "https://example.org/ns/sync", Local: "device"}, Value: "desktop"},
+				{Name: xml.Name{Space: "https://example.org/ns/sync", Local: "revision"}, Value: "42"},
+			},
+		},
+		Body: Body{
+			Outlines: []Outline{
+				{
+					Text:  "Programming",
+					Title: "Programming",
+					Attributes: []xml.Attr{
+						{Name: xml.Name{Space: "https://example.org/ns/sync", Local: "tags"}, Value: "dev,go"},
+					},
+					Outlines: []Outline{
+						{
+							Text:   "The Go Programming Language Blog",
+							Title:  "The Go Programming Language Blog",
+							Type:   OutlineTypeSubscription,
+							XmlUrl: "https://go.dev/blog/feed.atom",
+							Attributes: []xml.Attr{
+								{Name: xml.Name{Space: "https://example.org/ns/feed-health", Local: "status"}, Value: "ok"},
+								{Name: xml.Name{Space: "https://example.org/ns/feed-health", Local: "lastFetched"}, Value: "2024-11-07T20:18:01Z"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+)
+
+func TestMarshalFileExtension(t *testing.T) {
+	cases := []struct {
+		tname             string
+		document          Document
+		referenceFileName string
+	}{
+		{
+			tname:             "attributes",
+			document:          extensionDocumentAttributes,
+			referenceFileName: "attributes.opml",
+		},
+		{
+			tname:             "namespaces",
+			document:          extensionDocumentNamespaces,
+			referenceFileName: "namespaces.opml",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.tname, func(t *testing.T) {
+			referenceFilePath := filepath.Join("testdata", "extension", tc.referenceFileName)
+
+			wantBytes, err := os.ReadFile(referenceFilePath)
+			if err != nil {
+				t.Fatalf("failed to read reference output file: %q", err)
+			}
+
+			gotBytes, err := Marshal(&tc.document)
+			if err != nil {
+				t.Fatalf("want no error, got %q", err)
+			}
+
+			got := string(gotBytes)
+			want := string(wantBytes)
+
+			if got != want {
+				t.Errorf("\nwant:\n%s\n\ngot:\n%s", want, got)
+			}
+		})
+	}
+}
+
+func TestUnmarshalFileExtension(t *testing.T) {
+	cases := []struct {
+		tname         string
+		inputFileName string
+		want          Document
+	}{
+		{
+			tname:         "attributes",
+			inputFileName: "attributes.opml",
+			want:          extensionDocumentAttributes,
+		},
+		{
+			tname:         "namespaces",
+			inputFileName: "namespaces.opml",
+			want:          extensionDocumentNamespaces,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.tname, func(t *testing.T) {
+			inputFilePath := filepath.Join("testdata", "extension", tc.inputFileName)
+
+			got, err := UnmarshalFile(inputFilePath)
+			if err != nil {
+				t.Fatalf("want no error, got %q", err)
+			}
+
+			AssertDocumentsEqual(t, *got, tc.want)
+		})
+	}
+}
+
+func TestRoundtripExtension(t *testing.T) {
+	cases := []struct {
+		tname         string
+		inputFileName string
+	}{
+		{
+			tname:         "attributes",
+			inputFileName: "attributes.opml",
+		},
+		{
+			tname:         "namespaces",
+			inputFileName: "namespaces.opml",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.tname, func(t *testing.T) {
+			inputFilePath := filepath.Join("testdata", "extension", tc.inputFileName)
+
+			wantBytes, err := os.ReadFile(inputFilePath)
+			if err != nil {
+				t.Fatalf("failed to read input file: %q", err)
+			}
+
+			document, err := Unmarshal(wantBytes)
+			if err != nil {
+				t.Fatalf("want no error, got %q", err)
+			}
+
+			gotBytes, err := Marshal(document)
+			if err != nil {
+				t.Fatalf("want no error, got %q", err)
+			}
+
+			got := string(gotBytes)
+			want := string(wantBytes)
+
+			if got != want {
+				t.Errorf("\nwant:\n%s\n\ngot:\n%s", want, got)
+			}
+		})
+	}
+}
+
+func TestMarshalUndeclaredNamespace(t *testing.T) {
+	document := Document{
+		Version: Version2,
+		Namespaces: []Namespace{
+			{Prefix: "feeds", URI: "https://example.org/ns/sync"},
+		},
+		Body: Body{
+			Outlines: []Outline{
+				{
+					Text: "Lobsters",
+					Attributes: []xml.Attr{
+						{Name: xml.Name{Space: "https://example.org/ns/feeds", Local: "tags"}, Value: "news"},
+						{Name: xml.Name{Space: "https://example.org/ns/sync", Local: "revision"}, Value: "3"},
+						{Name: xml.Name{Space: "urn:example:health", Local: "status"}, Value: "ok"},
+					},
+				},
+			},
+		},
+	}
+
+	gotBytes, err := Marshal(&document)
+	if err != nil {
+		t.Fatalf("want no error, got %q", err)
+	}
+
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<opml version="2.0" xmlns:feeds="https://example.org/ns/sync" xmlns:feeds1="https://example.org/ns/feeds" xmlns:health="urn:example:health">
+  <head></head>
+  <body>
+    <outline text="Lobsters" feeds1:tags="news" feeds:revision="3" health:status="ok"></outline>
+  </body>
+</opml>`
+
+	if got := string(gotBytes); got != want {
+		t.Errorf("\nwant:\n%s\n\ngot:\n%s", want, got)
+	}
+}
+
+func TestRoundtripDefaultNamespace(t *testing.T) {
+	input := `<?xml version="1.0" encoding="UTF-8"?>
+<opml version="2.0" xmlns="https://example.org/ns/sync">
+  <head>
+    <title>Subscriptions</title>
+    <device>desktop</device>
+  </head>
+  <body>
+    <outline text="Lobsters"></outline>
+  </body>
+</opml>`
+
+	document, err := UnmarshalString(input)
+	if err != nil {
+		t.Fatalf("want no error, got %q", err)
+	}
+
+	wantElements := []Element{
+		{Name: xml.Name{Space: "https://example.org/ns/sync", Local: "device"}, Value: "desktop"},
+	}
+
+	if len(document.Head.Elements) != len(wantElements) || document.Head.Elements[0] != wantElements[0] {
+		t.Errorf("want Elements %v, got %v", wantElements, document.Head.Elements)
+	}
+
+	gotBytes, err := Marshal(document)
+	if err != nil {
+		t.Fatalf("want no error, got %q", err)
+	}
+
+	if got := string(gotBytes); got != input {
+		t.Errorf("\nwant:\n%s\n\ngot:\n%s", input, got)
+	}
+}
@@ -5,7 +5,9 @@ package opml
 
 import (
 	"encoding/xml"
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -175,3 +177,61 @@ func TestUnmarshalFeedReader(t *testing.T) {
 		})
 	}
 }
+
+func TestRoundtripFeedReader(t *testing.T) {
+	cases := []struct {
+		tname         string
+		inputFileName string
+		options       MarshalOptions
+	}{
+		{
+			tname:         "feedly",
+			inputFileName: "feedly.opml",
+			options: MarshalOptions{
+				Indent:              "    ",
+				SelfClosingOutlines: true,
+			},
+		},
+		{
+			tname:         "newsblur",
+			inputFileName: "newsblur.opml",
+			options: MarshalOptions{
+				SelfClosingOutlines: true,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.tname, func(t *testing.T) {
+			inputFilePath := filepath.Join("testdata", "feedreader", tc.inputFileName)
+
+			wantBytes, err := os.ReadFile(inputFilePath)
+			if err != nil {
+				t.Fatalf("failed to read input file: %q", err)
+			}
+
+			document, err := Unmarshal(wantBytes)
+			if err != nil {
+				t.Fatalf("want no error, got %q", err)
+			}
+
+			gotBytes, err := MarshalWithOptions(document, tc.options)
+			if err != nil {
+				t.Fatalf("want no error, got %q", err)
+			}
+
+			// The XML declaration and comments preceding the head are not preserved
+			headElement := func(data []byte) string {
+				s := string(data)
+				return strings.TrimSpace(s[strings.Index(s, "<head>"):])
+			}
+
+			got := headElement(gotBytes)
+			want := headElement(wantBytes)
+
+			if got != want {
+				t.Errorf("\nwant:\n%s\n\ngot:\n%s", want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright (c) VirtualTam
+// SPDX-License-Identifier: MIT
+
+package opml
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMarshalWithOptions(t *testing.T) {
+	document := Document{
+		Version: Version2,
+		Head: Head{
+			Title: "Abonnements",
+		},
+		Body: Body{
+			Outlines: []Outline{
+				{
+					Text: "Actualités",
+					Outlines: []Outline{
+						{Text: "Café ☕", Type: OutlineTypeSubscription, XmlUrl: "https://example.org/feed"},
+					},
+				},
+				{Text: "Vide"},
+			},
+		},
+	}
+
+	cases := []struct {
+		tname   string
+		options MarshalOptions
+		want    string
+	}{
+		{
+			tname:   "default",
+			options: MarshalOptions{},
+			want: `<?xml version="1.0" encoding="UTF-8"?>
+<opml version="2.0">
+  <head>
+    <title>Abonnements</title>
+  </head>
+  <body>
+    <outline text="Actualités">
+      <outline text="Café ☕" type="rss" xmlUrl="https://example.org/feed"></outline>
+    </outline>
+    <outline text="Vide"></outline>
+  </body>
+</opml>`,
+		},
+		{
+			tname: "indent with self-closing outlines",
+			options: MarshalOptions{
+				Indent:              "    ",
+				SelfClosingOutlines: true,
+			},
+			want: `<?xml version="1.0" encoding="UTF-8"?>
+<opml version="2.0">
+    <head>
+        <title>Abonnements</title>
+    </head>
+    <body>
+        <outline text="Actualités">
+            <outline text="Café ☕" type="rss" xmlUrl="https://example.org/feed"/>
+        </outline>
+        <outline text="Vide"/>
+    </body>
+</opml>`,
+		},
+		{
+			tname: "compact without XML declaration",
+			options: MarshalOptions{
+				Compact:            true,
+				OmitXMLDeclaration: true,
+			},
+			want: `<opml version="2.0"><head><title>Abonnements</title></head><body><outline text="Actualités"><outline text="Café ☕" type="rss" xmlUrl="https://example.org/feed"></outline></outline><outline text="Vide"></outline></body></opml>`,
+		},
+		{
+			tname: "compact ISO-8859-1",
+			options: MarshalOptions{
+				Compact:             true,
+				Charset:             "ISO-8859-1",
+				SelfClosingOutlines: true,
+			},
+			want: "<?xml version=\"1.0\" encoding=\"windows-1252\"?>" +
+				"<opml version=\"2.0\"><head><title>Abonnements</title></head><body>" +
+				"<outline text=\"Actualit\xe9s\"><outline text=\"Caf\xe9 &#9749;\" type=\"rss\" xmlUrl=\"https://example.org/feed\"/></outline>" +
+				"<outline text=\"Vide\"/></body></opml>",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.tname, func(t *testing.T) {
+			gotBytes, err := MarshalWithOptions(&document, tc.options)
+			if err != nil {
+				t.Fatalf("want no error, got %q", err)
+			}
+
+			if got := string(gotBytes); got != tc.want {
+				t.Errorf("\nwant:\n%s\n\ngot:\n%s", tc.want, got)
+			}
+
+			if tc.options.OmitXMLDeclaration {
+				return
+			}
+
+			decoded, err := Unmarshal(gotBytes)
+			if err != nil {
+				t.Fatalf("want no error, got %q", err)
+			}
+
+			AssertDocumentsEqual(t, *decoded, Document{
+				XMLName: decoded.XMLName,
+				Version: document.Version,
+				Head:    document.Head,
+				Body:    document.Body,
+			})
+		})
+	}
+}
+
+func TestMarshalWithOptionsUnsupportedCharset(t *testing.T) {
+	_, err := MarshalWithOptions(&Document{}, MarshalOptions{Charset: "EBCDIC-FR"})
+	if err == nil {
+		t.Error("want error, got none")
+	}
+}
+
+func TestEncoderSelfClosingOutlines(t *testing.T) {
+	var buf bytes.Buffer
+
+	encoder, err := NewEncoderWithOptions(&buf, MarshalOptions{SelfClosingOutlines: true})
+	if err != nil {
+		t.Fatalf("want no error, got %q", err)
+	}
+
+	if err := encoder.EncodeHead(&Document{Version: Version2}); err != nil {
+		t.Fatalf("want no error, got %q", err)
+	}
+
+	if err := encoder.StartOutline(&Outline{Text: "Empty folder"}); err != nil {
+		t.Fatalf("want no error, got %q", err)
+	}
+
+	// Flushing does not affect the output
+	if err := encoder.Flush(); err != nil {
+		t.Fatalf("want no error, got %q", err)
+	}
+
+	if err := encoder.EndOutline(); err != nil {
+		t.Fatalf("want no error, got %q", err)
+	}
+
+	if err := encoder.EncodeOutline(&Outline{Text: "Lobsters", Description: "</outline> & \"more\""}); err != nil {
+		t.Fatalf("want no error, got %q", err)
+	}
+
+	if err := encoder.Close(); err != nil {
+		t.Fatalf("want no error, got %q", err)
+	}
+
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<opml version="2.0">
+  <head></head>
+  <body>
+    <outline text="Empty folder"></outline>
+    <outline text="Lobsters" description="&lt;/outline&gt; &amp; &#34;more&#34;"/>
+  </body>
+</opml>`
+
+	if got := buf.String(); got != want {
+		t.Errorf("\nwant:\n%s\n\ngot:\n%s", want, got)
+	}
+}
@@ -112,6 +112,7 @@ var (
 			DateCreated:     mustDecodeRFC1123Time("Mon, 27 Feb 2006 12:09:48 GMT"),
 			DateModified:    mustDecodeRFC1123Time("Mon, 27 Feb 2006 12:11:44 GMT"),
 			OwnerName:       "Dave Winer",
+			OwnerId:         "http://www.opml.org/profiles/sendMail?usernum=1",
 			ExpansionState:  []int{1, 2, 5, 10, 13, 15},
 			VertScrollState: 1,
 			WindowTop:       242,
@@ -0,0 +1,218 @@
+// Copyright (c) VirtualTam
+// SPDX-License-Identifier: MIT
+
+package opml
+
+import (
+	"slices"
+)
+
+// A MergeResolver reconciles a subscription Outline of a merged Document with
+// an incoming subscription Outline that has the same feed URL, and returns the
+// resulting subscription Outline.
+type MergeResolver func(existing, incoming Outline) Outline
+
+// MergeOptions control the merging of OPML documents.
+//
+// The zero value uses ResolveFillMissing to reconcile duplicate subscriptions.
+type MergeOptions struct {
+	// Resolver reconciles subscriptions that have the same normalized feed URL.
+	Resolver MergeResolver
+}
+
+// ResolveFillMissing is the default MergeResolver.
+//
+// It keeps the fields of the existing Outline, sets its empty fields to the
+// values of the incoming Outline, and adds the categories and extension
+// attributes of the incoming Outline.
+func ResolveFillMissing(existing, incoming Outline) Outline {
+	fill := func(value *string, incoming string) {
+		if *value == "" {
+			*value = incoming
+		}
+	}
+
+	fill(&existing.Text, incoming.Text)
+	fill(&existing.Title, incoming.Title)
+	fill(&existing.Description, incoming.Description)
+	fill(&existing.HtmlUrl, incoming.HtmlUrl)
+	fill(&existing.Language, incoming.Language)
+
+	if existing.Version == "" {
+		existing.Version = incoming.Version
+	}
+	if existing.Created.IsZero() {
+		existing.Created = incoming.Created
+	}
+
+	for _, category := range incoming.Categories {
+		if !slices.Contains(existing.Categories, category) {
+			existing.Categories = append(existing.Categories, category)
+		}
+	}
+
+	for _, attr := range incoming.Attributes {
+		if _, ok := existing.Attr(attr.Name); !ok {
+			existing.Attributes = append(existing.Attributes, attr)
+		}
+	}
+
+	return existing
+}
+
+// Merge merges OPML documents into a new Document, using the default MergeOptions.
+func Merge(docs ...*Document) *Document {
+	return MergeWithOptions(MergeOptions{}, docs...)
+}
+
+// MergeWithOptions merges OPML documents into a new Document, using the given options.
+//
+// Outlines are merged in document order:
+//   - subscriptions with the same normalized feed URL are merged into the first
+//     subscription, using the MergeOptions Resolver;
+//   - other Outlines are merged with the sibling Outline that has the same text,
+//     type and URL, if any; their subordinated Outlines are merged recursively.
+//
+// The merged Head has the title and owner of the first Document that defines
+// them, the earliest creation date and the latest modification date. Outliner
+// view states are not merged.
+func MergeWithOptions(options MergeOptions, docs ...*Document) *Document {
+	resolver := options.Resolver
+	if resolver == nil {
+		resolver = ResolveFillMissing
+	}
+
+	merger := &merger{
+		document:      &Document{Version: Version2},
+		resolver:      resolver,
+		subscriptions: make(map[string]Path),
+	}
+
+	for _, doc := range docs {
+		merger.mergeHead(&doc.Head)
+
+		for _, namespace := range doc.Namespaces {
+			if !slices.Contains(merger.document.Namespaces, namespace) {
+				merger.document.Namespaces = append(merger.document.Namespaces, namespace)
+			}
+		}
+
+		merger.mergeOutlines(nil, doc.Body.Outlines)
+	}
+
+	return merger.document
+}
+
+type merger struct {
+	document *Document
+	resolver MergeResolver
+
+	// The Paths of the subscriptions of the merged Document, by normalized feed URL.
+	//
+	// As Outlines are only ever appended to the merged Document, Paths remain valid.
+	subscriptions map[string]Path
+}
+
+func (m *merger) mergeHead(head *Head) {
+	merged := &m.document.Head
+
+	fill := func(value *string, incoming string) {
+		if *value == "" {
+			*value = incoming
+		}
+	}
+
+	fill(&merged.Title, head.Title)
+	fill(&merged.OwnerName, head.OwnerName)
+	fill(&merged.OwnerEmail, head.OwnerEmail)
+	fill(&merged.OwnerId, head.OwnerId)
+	fill(&merged.Docs, head.Docs)
+
+	if !head.DateCreated.IsZero() && (merged.DateCreated.IsZero() || head.DateCreated.Before(merged.DateCreated)) {
+		merged.DateCreated = head.DateCreated
+		merged.dateCreatedSource = head.dateCreatedSource
+	}
+	if head.DateModified.After(merged.DateModified) {
+		merged.DateModified = head.DateModified
+		merged.dateModifiedSource = head.dateModifiedSource
+	}
+
+	for _, element := range head.Elements {
+		if _, ok := merged.Element(element.Name); !ok {
+			merged.Elements = append(merged.Elements, element)
+		}
+	}
+}
+
+// mergeOutlines merges outlines into the subordinated Outlines of the Outline
+// of the merged Document located at parent.
+func (m *merger) mergeOutlines(parent Path, outlines []Outline) {
+	for _, outline := range outlines {
+		if outline.OutlineType() == OutlineTypeSubscription && outline.XmlUrl != "" {
+			m.mergeSubscription(parent, outline)
+			continue
+		}
+
+		siblings := m.outlines(parent)
+
+		index := slices.IndexFunc(*siblings, func(o Outline) bool {
+			return o.Text == outline.Text && o.Type == outline.Type && o.Url == outline.Url
+		})
+
+		if index < 0 {
+			*siblings = append(*siblings, cloneOutlineAttributes(outline))
+			index = len(*siblings) - 1
+		}
+
+		path := append(parent.clone(), index)
+
+		m.mergeOutlines(path, outline.Outlines)
+	}
+}
+
+func (m *merger) mergeSubscription(parent Path, outline Outline) {
+	xmlUrl := NormalizeURL(outline.XmlUrl)
+
+	if path, ok := m.subscriptions[xmlUrl]; ok {
+		existing, _ := m.document.Get(path)
+
+		resolved := m.resolver(*existing, outline)
+		resolved.Outlines = existing.Outlines
+
+		*existing = resolved
+
+		m.mergeOutlines(path, outline.Outlines)
+
+		return
+	}
+
+	siblings := m.outlines(parent)
+	*siblings = append(*siblings, cloneOutlineAttributes(outline))
+
+	path := append(parent.clone(), len(*siblings)-1)
+	m.subscriptions[xmlUrl] = path
+
+	m.mergeOutlines(path, outline.Outlines)
+}
+
+// outlines returns the subordinated Outlines of the Outline of the merged
+// Document located at parent, or its top-level Outlines if parent is empty.
+func (m *merger) outlines(parent Path) *[]Outline {
+	if len(parent) == 0 {
+		return &m.document.Body.Outlines
+	}
+
+	outline, _ := m.document.Get(parent)
+
+	return &outline.Outlines
+}
+
+// cloneOutlineAttributes returns a copy of an Outline that does not share its
+// categories and extension attributes, without its subordinated Outlines.
+func cloneOutlineAttributes(outline Outline) Outline {
+	outline.Categories = slices.Clone(outline.Categories)
+	outline.Attributes = slices.Clone(outline.Attributes)
+	outline.Outlines = nil
+
+	return outline
+}
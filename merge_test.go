@@ -0,0 +1,219 @@
+// Copyright (c) VirtualTam
+// SPDX-License-Identifier: MIT
+
+package opml
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestMerge(t *testing.T) {
+	ours := &Document{
+		Version: Version1,
+		Head: Head{
+			Title:        "Ours",
+			DateCreated:  time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC),
+			DateModified: time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC),
+			OwnerName:    "Jane",
+		},
+		Body: Body{
+			Outlines: []Outline{
+				{
+					Text: "Programming",
+					Outlines: []Outline{
+						{
+							Text:       "Elixir Lang",
+							Type:       OutlineTypeSubscription,
+							XmlUrl:     "https://feeds.feedburner.com/ElixirLang",
+							Categories: []string{"elixir"},
+						},
+					},
+				},
+				{
+					Text:   "Lobsters",
+					Type:   OutlineTypeSubscription,
+					XmlUrl: "https://lobste.rs/rss",
+				},
+			},
+		},
+	}
+
+	theirs := &Document{
+		Version: Version2,
+		Head: Head{
+			Title:        "Theirs",
+			DateCreated:  time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC),
+			DateModified: time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC),
+			OwnerEmail:   "joe@example.org",
+		},
+		Body: Body{
+			Outlines: []Outline{
+				{
+					Text: "News",
+					Outlines: []Outline{
+						{
+							Text:    "Lobsters",
+							Title:   "Lobsters",
+							Type:    OutlineTypeSubscription,
+							HtmlUrl: "https://lobste.rs/",
+							XmlUrl:  "http://lobste.rs/rss/",
+						},
+					},
+				},
+				{
+					Text: "Programming",
+					Outlines: []Outline{
+						{
+							Text:       "Elixir",
+							Title:      "Elixir Lang",
+							Type:       OutlineTypeSubscription,
+							XmlUrl:     "http://feeds2.feedburner.com/ElixirLang",
+							Categories: []string{"programming", "elixir"},
+						},
+						{
+							Text:   "Go",
+							Type:   OutlineTypeSubscription,
+							XmlUrl: "https://go.dev/blog/feed.atom",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	yours := &Document{
+		Head: Head{
+			DateModified: time.Date(2024, time.July, 1, 0, 0, 0, 0, time.UTC),
+		},
+		Body: Body{
+			Outlines: []Outline{
+				{
+					Text: "News",
+					Outlines: []Outline{
+						{Text: "Hacker News", Type: OutlineTypeSubscription, XmlUrl: "https://news.ycombinator.com/rss"},
+					},
+				},
+			},
+		},
+	}
+
+	got := Merge(ours, theirs, yours)
+
+	want := Document{
+		Version: Version2,
+		Head: Head{
+			Title:        "Ours",
+			DateCreated:  time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC),
+			DateModified: time.Date(2024, time.July, 1, 0, 0, 0, 0, time.UTC),
+			OwnerName:    "Jane",
+			OwnerEmail:   "joe@example.org",
+		},
+		Body: Body{
+			Outlines: []Outline{
+				{
+					Text: "Programming",
+					Outlines: []Outline{
+						{
+							Text:       "Elixir Lang",
+							Title:      "Elixir Lang",
+							Type:       OutlineTypeSubscription,
+							XmlUrl:     "https://feeds.feedburner.com/ElixirLang",
+							Categories: []string{"elixir", "programming"},
+						},
+						{
+							Text:   "Go",
+							Type:   OutlineTypeSubscription,
+							XmlUrl: "https://go.dev/blog/feed.atom",
+						},
+					},
+				},
+				{
+					Text:    "Lobsters",
+					Title:   "Lobsters",
+					Type:    OutlineTypeSubscription,
+					HtmlUrl: "https://lobste.rs/",
+					XmlUrl:  "https://lobste.rs/rss",
+				},
+				{
+					Text: "News",
+					Outlines: []Outline{
+						{Text: "Hacker News", Type: OutlineTypeSubscription, XmlUrl: "https://news.ycombinator.com/rss"},
+					},
+				},
+			},
+		},
+	}
+
+	AssertDocumentsEqual(t, *got, want)
+
+	if got.Head.Title != want.Head.Title {
+		t.Errorf("want Head > Title %q, got %q", want.Head.Title, got.Head.Title)
+	}
+
+	// Merging does not modify the input documents
+	if categories := ours.Body.Outlines[0].Outlines[0].Categories; !slices.Equal(categories, []string{"elixir"}) {
+		t.Errorf("want input Categories to be unchanged, got %q", categories)
+	}
+}
+
+func TestMergeWithOptionsResolver(t *testing.T) {
+	ours := &Document{
+		Body: Body{
+			Outlines: []Outline{
+				{Text: "Lobsters", Title: "Lobsters", Type: OutlineTypeSubscription, XmlUrl: "https://lobste.rs/rss"},
+			},
+		},
+	}
+	theirs := &Document{
+		Body: Body{
+			Outlines: []Outline{
+				{Text: "lobste.rs", Title: "Lobsters (computing)", Type: OutlineTypeSubscription, XmlUrl: "https://lobste.rs/rss/"},
+			},
+		},
+	}
+
+	keepIncoming := func(existing, incoming Outline) Outline {
+		return incoming
+	}
+
+	got := MergeWithOptions(MergeOptions{Resolver: keepIncoming}, ours, theirs)
+
+	want := Document{
+		Version: Version2,
+		Body: Body{
+			Outlines: []Outline{
+				{Text: "lobste.rs", Title: "Lobsters (computing)", Type: OutlineTypeSubscription, XmlUrl: "https://lobste.rs/rss/"},
+			},
+		},
+	}
+
+	AssertDocumentsEqual(t, *got, want)
+}
+
+func TestMergeFeedReader(t *testing.T) {
+	got := Merge(&feedReaderDocumentFeedly, &feedReaderDocumentNewsblur)
+
+	gotSubscriptions := got.Subscriptions()
+	wantCount := len(feedReaderDocumentFeedly.Subscriptions()) + len(feedReaderDocumentNewsblur.Subscriptions())
+
+	if len(gotSubscriptions) != wantCount {
+		t.Errorf("want %d Subscriptions, got %d", wantCount, len(gotSubscriptions))
+	}
+
+	var gotFolders []string
+	for _, outline := range got.Body.Outlines {
+		gotFolders = append(gotFolders, outline.Text)
+	}
+
+	wantFolders := []string{"Programming", "Games", "Security", "Self-Hosted", "Cryptography"}
+
+	if !slices.Equal(gotFolders, wantFolders) {
+		t.Errorf("want folders %q, got %q", wantFolders, gotFolders)
+	}
+
+	if diagnostics := Validate(got); len(diagnostics) > 0 {
+		t.Errorf("want no diagnostics, got %v", diagnostics)
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright (c) VirtualTam
+// SPDX-License-Identifier: MIT
+
+package opml
+
+import (
+	"encoding/xml"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	xmlnsPrefix string = "xmlns"
+	xmlPrefix   string = "xml"
+	xmlURI      string = "http://www.w3.org/XML/1998/namespace"
+)
+
+// A Namespace binds an XML namespace URI to a prefix.
+//
+// An empty Prefix denotes the default namespace.
+type Namespace struct {
+	Prefix string `json:"prefix,omitempty"`
+	URI    string `json:"uri"`
+}
+
+// namespaces resolves the prefixes used to qualify extension attributes and
+// elements when marshaling a Document.
+type namespaces struct {
+	declared []Namespace
+	prefixes map[string]string
+
+	// The URI of the default namespace, if declared.
+	defaultURI string
+}
+
+// newNamespaces returns the namespaces declared by a Document, completed with
+// generated prefixes for the namespaces used by extensions that are not declared.
+func newNamespaces(d *Document) *namespaces {
+	ns := &namespaces{
+		prefixes: make(map[string]string),
+	}
+
+	for _, namespace := range d.Namespaces {
+		ns.declared = append(ns.declared, namespace)
+
+		if namespace.Prefix == "" {
+			ns.defaultURI = namespace.URI
+			continue
+		}
+
+		if _, ok := ns.prefixes[namespace.URI]; !ok {
+			ns.prefixes[namespace.URI] = namespace.Prefix
+		}
+	}
+
+	for _, element := range d.Head.Elements {
+		if element.Name.Space != ns.defaultURI {
+			ns.declare(element.Name.Space)
+		}
+	}
+
+	// Unprefixed attributes belong to no namespace, so attributes in the
+	// default namespace still need a prefix
+	for _, outline := range d.All() {
+		for _, attr := range outline.Attributes {
+			ns.declare(attr.Name.Space)
+		}
+	}
+
+	return ns
+}
+
+// declare ensures a prefix is bound to the given namespace URI.
+func (ns *namespaces) declare(uri string) {
+	switch uri {
+	case "", xmlnsPrefix, xmlURI:
+		return
+	}
+
+	if _, ok := ns.prefixes[uri]; ok {
+		return
+	}
+
+	prefix := ns.uniquePrefix(namespacePrefixCandidate(uri))
+
+	ns.declared = append(ns.declared, Namespace{Prefix: prefix, URI: uri})
+	ns.prefixes[uri] = prefix
+}
+
+func (ns *namespaces) uniquePrefix(candidate string) string {
+	taken := func(prefix string) bool {
+		for _, namespace := range ns.declared {
+			if namespace.Prefix == prefix {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if !taken(candidate) {
+		return candidate
+	}
+
+	for i := 1; ; i++ {
+		prefix := candidate + strconv.Itoa(i)
+		if !taken(prefix) {
+			return prefix
+		}
+	}
+}
+
+// declarations returns the xmlns attributes declaring all namespaces.
+func (ns *namespaces) declarations() []xml.Attr {
+	var attrs []xml.Attr
+
+	for _, namespace := range ns.declared {
+		name := xml.Name{Local: xmlnsPrefix}
+		if namespace.Prefix != "" {
+			name.Local = xmlnsPrefix + ":" + namespace.Prefix
+		}
+
+		attrs = append(attrs, xml.Attr{Name: name, Value: namespace.URI})
+	}
+
+	return attrs
+}
+
+// qualifyElement returns the prefixed form of an element name belonging to a
+// namespace, or its local name if it belongs to the default namespace.
+func (ns *namespaces) qualifyElement(name xml.Name) xml.Name {
+	if ns != nil && name.Space != "" && name.Space == ns.defaultURI {
+		return xml.Name{Local: name.Local}
+	}
+
+	return ns.qualify(name)
+}
+
+// qualify returns the prefixed form of a name belonging to a namespace.
+//
+// Names that belong to an unknown namespace are returned as is, and will be
+// qualified by the xml.Encoder.
+func (ns *namespaces) qualify(name xml.Name) xml.Name {
+	switch name.Space {
+	case "":
+		return name
+	case xmlnsPrefix:
+		return xml.Name{Local: xmlnsPrefix + ":" + name.Local}
+	case xmlURI:
+		return xml.Name{Local: xmlPrefix + ":" + name.Local}
+	}
+
+	if ns == nil {
+		return name
+	}
+
+	prefix, ok := ns.prefixes[name.Space]
+	if !ok {
+		return name
+	}
+
+	return xml.Name{Local: prefix + ":" + name.Local}
+}
+
+// namespacePrefixCandidate derives a prefix from the last element of a namespace URI,
+// falling back to "ns".
+func namespacePrefixCandidate(uri string) string {
+	candidate := strings.TrimRight(uri, "/")
+	if i := strings.LastIndexAny(candidate, "/:#"); i >= 0 {
+		candidate = candidate[i+1:]
+	}
+
+	if !isNCName(candidate) || strings.HasPrefix(strings.ToLower(candidate), xmlPrefix) {
+		return "ns"
+	}
+
+	return candidate
+}
+
+// isNCName returns whether s is a valid non-colonized XML name.
+func isNCName(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for i, r := range s {
+		switch {
+		case unicode.IsLetter(r) || r == '_':
+		case i > 0 && (unicode.IsDigit(r) || r == '-' || r == '.'):
+		default:
+			return false
+		}
+	}
+
+	return true
+}
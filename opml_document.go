@@ -4,8 +4,12 @@
 package opml
 
 import (
+	"cmp"
 	"encoding/json"
 	"encoding/xml"
+	"fmt"
+	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -38,6 +42,46 @@ type Document struct {
 	Version string   `xml:"version,attr" json:"version"`
 	Head    Head     `xml:"head" json:"head"`
 	Body    Body     `xml:"body" json:"body"`
+
+	// Namespaces declared on the <opml> root element, in document order.
+	//
+	// Namespaces used by extension attributes and elements that are not
+	// declared here are assigned a prefix when the Document is marshaled.
+	Namespaces []Namespace `xml:"-" json:"namespaces,omitempty"`
+}
+
+// SetNamespace declares a namespace on the Document, binding it to the given prefix.
+//
+// If the prefix is already bound, the corresponding namespace URI is replaced.
+func (d *Document) SetNamespace(prefix, uri string) {
+	for i, ns := range d.Namespaces {
+		if ns.Prefix == prefix {
+			d.Namespaces[i].URI = uri
+			return
+		}
+	}
+
+	d.Namespaces = append(d.Namespaces, Namespace{Prefix: prefix, URI: uri})
+}
+
+func (d *Document) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	encoder := &Encoder{encoder: e}
+
+	return encoder.encodeDocument(d)
+}
+
+func (d *Document) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error {
+	decoder := &Decoder{decoder: dec}
+	decoder.decodeRoot(start)
+
+	document, err := decoder.decodeDocument()
+	if err != nil {
+		return err
+	}
+
+	*d = *document
+
+	return nil
 }
 
 // A Head contains the metadata for the OPML Document.
@@ -57,6 +101,13 @@ type Head struct {
 	// The email address of the owner of the document.
 	OwnerEmail string
 
+	// The http address of a web page that contains a form allowing a human
+	// reader to communicate with the owner of the document.
+	OwnerId string
+
+	// The http address of documentation for the format used in the OPML file.
+	Docs string
+
 	// A list of line numbers that are expanded.
 	// The line numbers in the list indicate which headlines to expand.
 	ExpansionState []int
@@ -75,16 +126,73 @@ type Head struct {
 
 	// The pixel location of the right edge of the window.
 	WindowRight int
+
+	// Extension: Elements that are not defined by the OPML specification, in document order.
+	Elements []Element
+
+	// The textual representation of dates, as decoded.
+	dateCreatedSource  dateSource
+	dateModifiedSource dateSource
+}
+
+// Element returns the value of the extension element with the given name.
+func (h *Head) Element(name xml.Name) (string, bool) {
+	for _, element := range h.Elements {
+		if element.Name == name {
+			return element.Value, true
+		}
+	}
+
+	return "", false
+}
+
+// SetElement sets the value of the extension element with the given name,
+// adding it to the Head if it does not exist.
+func (h *Head) SetElement(name xml.Name, value string) {
+	for i, element := range h.Elements {
+		if element.Name == name {
+			h.Elements[i].Value = value
+			return
+		}
+	}
+
+	h.Elements = append(h.Elements, Element{Name: name, Value: value})
+}
+
+// RemoveElement removes the extension element with the given name.
+func (h *Head) RemoveElement(name xml.Name) {
+	for i, element := range h.Elements {
+		if element.Name == name {
+			h.Elements = append(h.Elements[:i], h.Elements[i+1:]...)
+			return
+		}
+	}
 }
 
 func (h *Head) MarshalJSON() ([]byte, error) {
-	mHead := newMarshalableHead(h)
+	mHead := newMarshalableHead(h, nil)
 
 	return json.Marshal(mHead)
 }
 
+func (h *Head) UnmarshalJSON(data []byte) error {
+	var mHead marshalableHead
+	if err := json.Unmarshal(data, &mHead); err != nil {
+		return err
+	}
+
+	head, err := mHead.toHead(nil)
+	if err != nil {
+		return err
+	}
+
+	*h = head
+
+	return nil
+}
+
 func (h *Head) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
-	mHead := newMarshalableHead(h)
+	mHead := newMarshalableHead(h, nil)
 
 	return e.EncodeElement(mHead, start)
 }
@@ -95,7 +203,7 @@ func (h *Head) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 		return err
 	}
 
-	head, err := mHead.toHead()
+	head, err := mHead.toHead(nil)
 	if err != nil {
 		return err
 	}
@@ -111,19 +219,34 @@ type marshalableHead struct {
 	DateModifiedStr    string `xml:"dateModified,omitempty" json:"date_modified,omitempty"`
 	OwnerName          string `xml:"ownerName,omitempty" json:"owner_name,omitempty"`
 	OwnerEmail         string `xml:"ownerEmail,omitempty" json:"owner_email,omitempty"`
+	OwnerId            string `xml:"ownerId,omitempty" json:"owner_id,omitempty"`
+	Docs               string `xml:"docs,omitempty" json:"docs,omitempty"`
 	ExpansionStatesStr string `xml:"expansionState,omitempty" json:"expansion_state,omitempty"`
-	VertScrollState    int    `xml:"vertScrollState,omitempty" json:"vert_scroll_state,omitempty"`
-	WindowTop          int    `xml:"windowTop,omitempty" json:"window_top,omitempty"`
-	WindowLeft         int    `xml:"windowLeft,omitempty" json:"window_left,omitempty"`
-	WindowBottom       int    `xml:"windowBottom,omitempty" json:"window_bottom,omitempty"`
-	WindowRight        int    `xml:"windowRight,omitempty" json:"window_right,omitempty"`
+
+	// Integer values are decoded from XML as strings, so that invalid values
+	// can be reported as warnings
+	VertScrollStateStr string `xml:"vertScrollState,omitempty" json:"-"`
+	WindowTopStr       string `xml:"windowTop,omitempty" json:"-"`
+	WindowLeftStr      string `xml:"windowLeft,omitempty" json:"-"`
+	WindowBottomStr    string `xml:"windowBottom,omitempty" json:"-"`
+	WindowRightStr     string `xml:"windowRight,omitempty" json:"-"`
+
+	VertScrollState int `xml:"-" json:"vert_scroll_state,omitempty"`
+	WindowTop       int `xml:"-" json:"window_top,omitempty"`
+	WindowLeft      int `xml:"-" json:"window_left,omitempty"`
+	WindowBottom    int `xml:"-" json:"window_bottom,omitempty"`
+	WindowRight     int `xml:"-" json:"window_right,omitempty"`
+
+	Elements elements `xml:",any" json:"elements,omitempty"`
 }
 
-func newMarshalableHead(h *Head) marshalableHead {
+func newMarshalableHead(h *Head, ns *namespaces) marshalableHead {
 	mHead := marshalableHead{
 		Title:           h.Title,
 		OwnerName:       h.OwnerName,
 		OwnerEmail:      h.OwnerEmail,
+		OwnerId:         h.OwnerId,
+		Docs:            h.Docs,
 		VertScrollState: h.VertScrollState,
 		WindowTop:       h.WindowTop,
 		WindowLeft:      h.WindowLeft,
@@ -132,10 +255,10 @@ func newMarshalableHead(h *Head) marshalableHead {
 	}
 
 	if !h.DateCreated.IsZero() {
-		mHead.DateCreatedStr = encodeRFC1123Time(h.DateCreated)
+		mHead.DateCreatedStr = h.dateCreatedSource.encode(h.DateCreated)
 	}
 	if !h.DateModified.IsZero() {
-		mHead.DateModifiedStr = encodeRFC1123Time(h.DateModified)
+		mHead.DateModifiedStr = h.dateModifiedSource.encode(h.DateModified)
 	}
 
 	if len(h.ExpansionState) > 0 {
@@ -147,14 +270,29 @@ func newMarshalableHead(h *Head) marshalableHead {
 		mHead.ExpansionStatesStr = strings.Join(statesStr, ", ")
 	}
 
+	mHead.VertScrollStateStr = encodeHeadInt(h.VertScrollState)
+	mHead.WindowTopStr = encodeHeadInt(h.WindowTop)
+	mHead.WindowLeftStr = encodeHeadInt(h.WindowLeft)
+	mHead.WindowBottomStr = encodeHeadInt(h.WindowBottom)
+	mHead.WindowRightStr = encodeHeadInt(h.WindowRight)
+
+	for _, element := range h.Elements {
+		mHead.Elements = append(mHead.Elements, marshalableElement{
+			XMLName: ns.qualifyElement(element.Name),
+			Value:   element.Value,
+		})
+	}
+
 	return mHead
 }
 
-func (mHead *marshalableHead) toHead() (Head, error) {
+func (mHead *marshalableHead) toHead(warn warnFunc) (Head, error) {
 	h := Head{
 		Title:           mHead.Title,
 		OwnerName:       mHead.OwnerName,
 		OwnerEmail:      mHead.OwnerEmail,
+		OwnerId:         mHead.OwnerId,
+		Docs:            mHead.Docs,
 		VertScrollState: mHead.VertScrollState,
 		WindowTop:       mHead.WindowTop,
 		WindowLeft:      mHead.WindowLeft,
@@ -164,20 +302,22 @@ func (mHead *marshalableHead) toHead() (Head, error) {
 
 	if mHead.DateCreatedStr != "" {
 		dateCreated, err := decodeTime(mHead.DateCreatedStr)
-		if err != nil {
+		if err := warn.handle(err, "dateCreated"); err != nil {
 			return Head{}, err
 		}
 
 		h.DateCreated = dateCreated
+		h.dateCreatedSource = newDateSource(mHead.DateCreatedStr, dateCreated)
 	}
 
 	if mHead.DateModifiedStr != "" {
 		dateModified, err := decodeTime(mHead.DateModifiedStr)
-		if err != nil {
+		if err := warn.handle(err, "dateModified"); err != nil {
 			return Head{}, err
 		}
 
 		h.DateModified = dateModified
+		h.dateModifiedSource = newDateSource(mHead.DateModifiedStr, dateModified)
 	}
 
 	if mHead.ExpansionStatesStr != "" {
@@ -193,7 +333,11 @@ func (mHead *marshalableHead) toHead() (Head, error) {
 
 			state, err := strconv.Atoi(stateStr)
 			if err != nil {
-				return Head{}, err
+				if err := warn.handle(err, "expansionState"); err != nil {
+					return Head{}, err
+				}
+
+				continue
 			}
 
 			expansionStates = append(expansionStates, state)
@@ -202,9 +346,125 @@ func (mHead *marshalableHead) toHead() (Head, error) {
 		h.ExpansionState = expansionStates
 	}
 
+	intFields := []struct {
+		name  string
+		value string
+		field *int
+	}{
+		{name: "vertScrollState", value: mHead.VertScrollStateStr, field: &h.VertScrollState},
+		{name: "windowTop", value: mHead.WindowTopStr, field: &h.WindowTop},
+		{name: "windowLeft", value: mHead.WindowLeftStr, field: &h.WindowLeft},
+		{name: "windowBottom", value: mHead.WindowBottomStr, field: &h.WindowBottom},
+		{name: "windowRight", value: mHead.WindowRightStr, field: &h.WindowRight},
+	}
+
+	for _, intField := range intFields {
+		if intField.value == "" {
+			continue
+		}
+
+		value, err := strconv.Atoi(strings.TrimSpace(intField.value))
+		if err := warn.handle(err, intField.name); err != nil {
+			return Head{}, err
+		}
+
+		*intField.field = value
+	}
+
+	for _, mElement := range mHead.Elements {
+		h.Elements = append(h.Elements, Element{
+			Name:  mElement.XMLName,
+			Value: mElement.Value,
+		})
+	}
+
 	return h, nil
 }
 
+// encodeHeadInt returns the textual representation of an integer Head field,
+// or an empty string if it is unset.
+func encodeHeadInt(value int) string {
+	if value == 0 {
+		return ""
+	}
+
+	return strconv.Itoa(value)
+}
+
+// An Element represents a Head element that is not defined by the OPML specification,
+// such as a namespaced extension element.
+type Element struct {
+	Name  xml.Name
+	Value string
+}
+
+type marshalableElement struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+// elements holds the XML elements of a Head that are not defined by the
+// OPML specification.
+type elements []marshalableElement
+
+func (e elements) MarshalJSON() ([]byte, error) {
+	mExtensions := make([]marshalableExtension, len(e))
+
+	for i, element := range e {
+		mExtensions[i] = marshalableExtension{
+			Space: element.XMLName.Space,
+			Name:  element.XMLName.Local,
+			Value: element.Value,
+		}
+	}
+
+	return json.Marshal(mExtensions)
+}
+
+func (e *elements) UnmarshalJSON(data []byte) error {
+	var mExtensions []marshalableExtension
+	if err := json.Unmarshal(data, &mExtensions); err != nil {
+		return err
+	}
+
+	mElements := make(elements, len(mExtensions))
+
+	for i, mExtension := range mExtensions {
+		mElements[i] = marshalableElement{
+			XMLName: xml.Name{Space: mExtension.Space, Local: mExtension.Name},
+			Value:   mExtension.Value,
+		}
+	}
+
+	*e = mElements
+
+	return nil
+}
+
+// A warnFunc reports invalid field values as warnings when decoding leniently.
+//
+// When a warnFunc is nil, decoding is strict and invalid field values are
+// returned as errors.
+type warnFunc func(err error)
+
+// handle qualifies a non-nil error with the name of the field being decoded,
+// and either returns it when decoding strictly, or reports it as a warning.
+func (warn warnFunc) handle(err error, field string) error {
+	if err == nil {
+		return nil
+	}
+
+	err = fmt.Errorf("%s: %w", field, err)
+
+	if warn == nil {
+		return err
+	}
+
+	warn(err)
+
+	return nil
+}
+
 // A Body contains one or more Outline elements.
 type Body struct {
 	Outlines []Outline `xml:"outline" json:"outlines"`
@@ -255,6 +515,25 @@ type Outline struct {
 
 	// Directory: Subordinated outlines, arbitrarily structured.
 	Outlines []Outline
+
+	// Extension: Attributes that are not defined by the OPML specification, in document order.
+	Attributes []xml.Attr
+
+	// The location of the outline element in the source document, if the Outline
+	// was decoded by a Decoder.
+	Position Position
+
+	// The URL of the included document the outline was grafted from, if it was
+	// resolved by Document.ResolveIncludes.
+	//
+	// Like the Position, it is only kept in memory, and is not marshaled.
+	Source string
+
+	// The textual representation of the creation date, as decoded.
+	createdSource dateSource
+
+	// The names of the attributes of the outline element, in decoded order.
+	attrOrder []xml.Name
 }
 
 // IsDirectory returns whether this Outline is a directory and contains subordinated Outlines.
@@ -262,6 +541,25 @@ func (o *Outline) IsDirectory() bool {
 	return len(o.Outlines) > 0
 }
 
+// IsInclusion returns whether this Outline includes another OPML document.
+//
+// Inclusion Outlines, and link Outlines whose url ends in .opml, are inclusions.
+func (o *Outline) IsInclusion() bool {
+	switch o.Type {
+	case OutlineTypeInclusion:
+		return true
+	case OutlineTypeLink:
+		u, err := url.Parse(o.Url)
+		if err != nil {
+			return false
+		}
+
+		return strings.HasSuffix(strings.ToLower(u.Path), ".opml")
+	}
+
+	return false
+}
+
 // OutlineType returns the type of this Outline.
 //
 // If the Type is not set explicitly, it is inferred from the Outline's field values.
@@ -273,25 +571,53 @@ func (o *Outline) OutlineType() OutlineType {
 	return OutlineTypeText
 }
 
-func (o *Outline) MarshalJSON() ([]byte, error) {
-	mOutline := newMarshalableOutline(o)
+// Attr returns the value of the extension attribute with the given name.
+func (o *Outline) Attr(name xml.Name) (string, bool) {
+	for _, attr := range o.Attributes {
+		if attr.Name == name {
+			return attr.Value, true
+		}
+	}
 
-	return json.Marshal(mOutline)
+	return "", false
 }
 
-func (o *Outline) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+// SetAttr sets the value of the extension attribute with the given name,
+// adding it to the Outline if it does not exist.
+func (o *Outline) SetAttr(name xml.Name, value string) {
+	for i, attr := range o.Attributes {
+		if attr.Name == name {
+			o.Attributes[i].Value = value
+			return
+		}
+	}
+
+	o.Attributes = append(o.Attributes, xml.Attr{Name: name, Value: value})
+}
+
+// RemoveAttr removes the extension attribute with the given name.
+func (o *Outline) RemoveAttr(name xml.Name) {
+	for i, attr := range o.Attributes {
+		if attr.Name == name {
+			o.Attributes = append(o.Attributes[:i], o.Attributes[i+1:]...)
+			return
+		}
+	}
+}
+
+func (o *Outline) MarshalJSON() ([]byte, error) {
 	mOutline := newMarshalableOutline(o)
 
-	return e.EncodeElement(mOutline, start)
+	return json.Marshal(mOutline)
 }
 
-func (o *Outline) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+func (o *Outline) UnmarshalJSON(data []byte) error {
 	var mOutline marshalableOutline
-	if err := d.DecodeElement(&mOutline, &start); err != nil {
+	if err := json.Unmarshal(data, &mOutline); err != nil {
 		return err
 	}
 
-	outline, err := mOutline.toOutline()
+	outline, err := mOutline.toOutline(nil)
 	if err != nil {
 		return err
 	}
@@ -301,23 +627,103 @@ func (o *Outline) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 	return nil
 }
 
-type marshalableOutline struct {
-	Text string `xml:"text,attr" json:"text"`
+func (o *Outline) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return encodeOutline(e, o, start, nil)
+}
 
-	CategoriesStr string      `xml:"category,attr,omitempty" json:"categories,omitempty"`
-	CreatedStr    string      `xml:"created,attr,omitempty" json:"created,omitempty"`
-	Description   string      `xml:"description,attr,omitempty" json:"description,omitempty"`
-	HtmlUrl       string      `xml:"htmlUrl,attr,omitempty" json:"html_url,omitempty"`
-	IsBreakpoint  bool        `xml:"isBreakpoint,attr,omitempty" json:"is_breakpoint,omitempty"`
-	IsComment     bool        `xml:"isComment,attr,omitempty" json:"is_comment,omitempty"`
-	Language      string      `xml:"language,attr,omitempty" json:"language,omitempty"`
-	Title         string      `xml:"title,attr,omitempty" json:"title,omitempty"`
-	Type          OutlineType `xml:"type,attr,omitempty" json:"type,omitempty"`
-	Url           string      `xml:"url,attr,omitempty" json:"url,omitempty"`
-	Version       RSSVersion  `xml:"version,attr,omitempty" json:"version,omitempty"`
-	XmlUrl        string      `xml:"xmlUrl,attr,omitempty" json:"xml_url,omitempty"`
+var outlineStartElement = xml.StartElement{Name: xml.Name{Local: "outline"}}
 
-	Outlines []Outline `xml:"outline" json:"outlines,omitempty"`
+// encodeOutline writes the XML encoding of an Outline and its subordinated
+// Outlines, using the given namespaces to qualify extension attributes.
+func encodeOutline(e *xml.Encoder, o *Outline, start xml.StartElement, ns *namespaces) error {
+	mOutline := newMarshalableOutline(o)
+
+	start.Attr = mOutline.xmlAttrs(ns)
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	for i := range o.Outlines {
+		if err := encodeOutline(e, &o.Outlines[i], outlineStartElement, ns); err != nil {
+			return err
+		}
+	}
+
+	return e.EncodeToken(start.End())
+}
+
+func (o *Outline) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	outline, err := decodeOutlineStartElement(start, nil)
+	if err != nil {
+		return err
+	}
+
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if t.Name.Local != outlineStartElement.Name.Local {
+				if err := d.Skip(); err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			var child Outline
+			if err := d.DecodeElement(&child, &t); err != nil {
+				return err
+			}
+
+			outline.Outlines = append(outline.Outlines, child)
+
+		case xml.EndElement:
+			*o = outline
+
+			return nil
+		}
+	}
+}
+
+// decodeOutlineStartElement returns the Outline described by the attributes of
+// an <outline> start element, without its subordinated Outlines.
+//
+// If warn is not nil, invalid attribute values are reported as warnings and left unset.
+func decodeOutlineStartElement(start xml.StartElement, warn warnFunc) (Outline, error) {
+	var mOutline marshalableOutline
+	if err := mOutline.setXMLAttrs(start.Attr, warn); err != nil {
+		return Outline{}, err
+	}
+
+	return mOutline.toOutline(warn)
+}
+
+type marshalableOutline struct {
+	Text string `json:"text"`
+
+	CategoriesStr string      `json:"categories,omitempty"`
+	CreatedStr    string      `json:"created,omitempty"`
+	Description   string      `json:"description,omitempty"`
+	HtmlUrl       string      `json:"html_url,omitempty"`
+	IsBreakpoint  bool        `json:"is_breakpoint,omitempty"`
+	IsComment     bool        `json:"is_comment,omitempty"`
+	Language      string      `json:"language,omitempty"`
+	Title         string      `json:"title,omitempty"`
+	Type          OutlineType `json:"type,omitempty"`
+	Url           string      `json:"url,omitempty"`
+	Version       RSSVersion  `json:"version,omitempty"`
+	XmlUrl        string      `json:"xml_url,omitempty"`
+
+	Attributes attributes `json:"attributes,omitempty"`
+
+	Outlines []Outline `json:"outlines,omitempty"`
+
+	attrOrder []xml.Name
 }
 
 func newMarshalableOutline(o *Outline) marshalableOutline {
@@ -338,17 +744,134 @@ func newMarshalableOutline(o *Outline) marshalableOutline {
 		IsBreakpoint: o.IsBreakpoint,
 		IsComment:    o.IsComment,
 
+		Attributes: o.Attributes,
+
 		Outlines: o.Outlines,
+
+		attrOrder: o.attrOrder,
 	}
 
 	if !o.Created.IsZero() {
-		mOutline.CreatedStr = encodeRFC1123Time(o.Created)
+		mOutline.CreatedStr = o.createdSource.encode(o.Created)
 	}
 
 	return mOutline
 }
 
-func (mo *marshalableOutline) toOutline() (Outline, error) {
+// xmlAttrs returns the XML attributes of the Outline, in the same order as the
+// marshalableOutline struct fields, or in decoded order if the Outline was decoded.
+func (mo *marshalableOutline) xmlAttrs(ns *namespaces) []xml.Attr {
+	attrs := []xml.Attr{
+		{Name: xml.Name{Local: "text"}, Value: mo.Text},
+	}
+
+	appendAttr := func(name, value string) {
+		if value == "" {
+			return
+		}
+
+		attrs = append(attrs, xml.Attr{Name: xml.Name{Local: name}, Value: value})
+	}
+
+	appendAttr("category", mo.CategoriesStr)
+	appendAttr("created", mo.CreatedStr)
+	appendAttr("description", mo.Description)
+	appendAttr("htmlUrl", mo.HtmlUrl)
+	if mo.IsBreakpoint {
+		appendAttr("isBreakpoint", strconv.FormatBool(mo.IsBreakpoint))
+	}
+	if mo.IsComment {
+		appendAttr("isComment", strconv.FormatBool(mo.IsComment))
+	}
+	appendAttr("language", mo.Language)
+	appendAttr("title", mo.Title)
+	appendAttr("type", string(mo.Type))
+	appendAttr("url", mo.Url)
+	appendAttr("version", string(mo.Version))
+	appendAttr("xmlUrl", mo.XmlUrl)
+
+	attrs = append(attrs, mo.Attributes...)
+
+	if len(mo.attrOrder) > 0 {
+		// Attributes that were not decoded are written last
+		position := func(attr xml.Attr) int {
+			if i := slices.Index(mo.attrOrder, attr.Name); i >= 0 {
+				return i
+			}
+
+			return len(mo.attrOrder)
+		}
+
+		slices.SortStableFunc(attrs, func(a, b xml.Attr) int {
+			return cmp.Compare(position(a), position(b))
+		})
+	}
+
+	for i, attr := range attrs {
+		attrs[i].Name = ns.qualify(attr.Name)
+	}
+
+	return attrs
+}
+
+// setXMLAttrs sets the fields corresponding to the given XML attributes.
+//
+// Attributes that are not defined by the OPML specification are kept as extension attributes.
+func (mo *marshalableOutline) setXMLAttrs(attrs []xml.Attr, warn warnFunc) error {
+	for _, attr := range attrs {
+		mo.attrOrder = append(mo.attrOrder, attr.Name)
+
+		if attr.Name.Space != "" {
+			mo.Attributes = append(mo.Attributes, attr)
+			continue
+		}
+
+		switch attr.Name.Local {
+		case "text":
+			mo.Text = attr.Value
+		case "category":
+			mo.CategoriesStr = attr.Value
+		case "created":
+			mo.CreatedStr = attr.Value
+		case "description":
+			mo.Description = attr.Value
+		case "htmlUrl":
+			mo.HtmlUrl = attr.Value
+		case "isBreakpoint":
+			isBreakpoint, err := strconv.ParseBool(strings.TrimSpace(attr.Value))
+			if err := warn.handle(err, "isBreakpoint"); err != nil {
+				return err
+			}
+
+			mo.IsBreakpoint = isBreakpoint
+		case "isComment":
+			isComment, err := strconv.ParseBool(strings.TrimSpace(attr.Value))
+			if err := warn.handle(err, "isComment"); err != nil {
+				return err
+			}
+
+			mo.IsComment = isComment
+		case "language":
+			mo.Language = attr.Value
+		case "title":
+			mo.Title = attr.Value
+		case "type":
+			mo.Type = OutlineType(attr.Value)
+		case "url":
+			mo.Url = attr.Value
+		case "version":
+			mo.Version = RSSVersion(attr.Value)
+		case "xmlUrl":
+			mo.XmlUrl = attr.Value
+		default:
+			mo.Attributes = append(mo.Attributes, attr)
+		}
+	}
+
+	return nil
+}
+
+func (mo *marshalableOutline) toOutline(warn warnFunc) (Outline, error) {
 	outline := Outline{
 		// Text fields
 		Text: mo.Text,
@@ -372,20 +895,71 @@ func (mo *marshalableOutline) toOutline() (Outline, error) {
 
 		// Directory fields
 		Outlines: mo.Outlines,
+
+		// Extension fields
+		Attributes: mo.Attributes,
+
+		attrOrder: mo.attrOrder,
 	}
 
-	if mo.CategoriesStr != "" {
-		outline.Categories = strings.Split(mo.CategoriesStr, ",")
+	for _, category := range ParseCategories(mo.CategoriesStr) {
+		outline.Categories = append(outline.Categories, string(category))
 	}
 
 	if mo.CreatedStr != "" {
 		created, err := decodeTime(mo.CreatedStr)
-		if err != nil {
+		if err := warn.handle(err, "created"); err != nil {
 			return Outline{}, err
 		}
 
 		outline.Created = created
+		outline.createdSource = newDateSource(mo.CreatedStr, created)
 	}
 
 	return outline, nil
 }
+
+// attributes holds the XML attributes of an Outline that are not defined by the
+// OPML specification.
+type attributes []xml.Attr
+
+func (a attributes) MarshalJSON() ([]byte, error) {
+	mExtensions := make([]marshalableExtension, len(a))
+
+	for i, attr := range a {
+		mExtensions[i] = marshalableExtension{
+			Space: attr.Name.Space,
+			Name:  attr.Name.Local,
+			Value: attr.Value,
+		}
+	}
+
+	return json.Marshal(mExtensions)
+}
+
+func (a *attributes) UnmarshalJSON(data []byte) error {
+	var mExtensions []marshalableExtension
+	if err := json.Unmarshal(data, &mExtensions); err != nil {
+		return err
+	}
+
+	attrs := make(attributes, len(mExtensions))
+
+	for i, mExtension := range mExtensions {
+		attrs[i] = xml.Attr{
+			Name:  xml.Name{Space: mExtension.Space, Local: mExtension.Name},
+			Value: mExtension.Value,
+		}
+	}
+
+	*a = attrs
+
+	return nil
+}
+
+// marshalableExtension is the JSON representation of an extension attribute or element.
+type marshalableExtension struct {
+	Space string `json:"space,omitempty"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
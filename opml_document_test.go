@@ -4,6 +4,8 @@
 package opml
 
 import (
+	"encoding/json"
+	"encoding/xml"
 	"fmt"
 	"testing"
 
@@ -152,3 +154,275 @@ func TestOutlineOutlineType(t *testing.T) {
 		})
 	}
 }
+
+func TestOutlineMarshalJSONAttributes(t *testing.T) {
+	outline := Outline{
+		Text: "Git Rev News",
+		Attributes: []xml.Attr{
+			{Name: xml.Name{Local: "unread"}, Value: "12"},
+			{Name: xml.Name{Space: "http://example.org/ns", Local: "tag"}, Value: "git"},
+		},
+	}
+
+	got, err := json.Marshal(&outline)
+	if err != nil {
+		t.Fatalf("want no error, got %q", err)
+	}
+
+	want := `{"text":"Git Rev News","attributes":[{"name":"unread","value":"12"},{"space":"http://example.org/ns","name":"tag","value":"git"}]}`
+
+	if string(got) != want {
+		t.Errorf("want %s, got %s", want, got)
+	}
+}
+
+func TestHeadMarshal(t *testing.T) {
+	head := Head{
+		Title:   "Feed subscriptions",
+		OwnerId: "https://example.org/contact",
+		Docs:    "https://opml.org/spec2.opml",
+	}
+
+	t.Run("json", func(t *testing.T) {
+		got, err := json.Marshal(&head)
+		if err != nil {
+			t.Fatalf("want no error, got %q", err)
+		}
+
+		want := `{"title":"Feed subscriptions","owner_id":"https://example.org/contact","docs":"https://opml.org/spec2.opml"}`
+
+		if string(got) != want {
+			t.Errorf("want %s, got %s", want, got)
+		}
+	})
+
+	t.Run("xml", func(t *testing.T) {
+		got, err := xml.Marshal(&head)
+		if err != nil {
+			t.Fatalf("want no error, got %q", err)
+		}
+
+		want := `<Head><title>Feed subscriptions</title><ownerId>https://example.org/contact</ownerId><docs>https://opml.org/spec2.opml</docs></Head>`
+
+		if string(got) != want {
+			t.Errorf("want %s, got %s", want, got)
+		}
+
+		var decoded Head
+		if err := xml.Unmarshal(got, &decoded); err != nil {
+			t.Fatalf("want no error, got %q", err)
+		}
+
+		if decoded.OwnerId != head.OwnerId {
+			t.Errorf("want OwnerId %q, got %q", head.OwnerId, decoded.OwnerId)
+		}
+		if decoded.Docs != head.Docs {
+			t.Errorf("want Docs %q, got %q", head.Docs, decoded.Docs)
+		}
+	})
+}
+
+func TestOutlineAttr(t *testing.T) {
+	nameTags := xml.Name{Space: "https://example.org/ns/sync", Local: "tags"}
+	nameUnread := xml.Name{Local: "unread"}
+
+	outline := Outline{Text: "Lobsters"}
+
+	if _, ok := outline.Attr(nameTags); ok {
+		t.Fatalf("want no attribute %v", nameTags)
+	}
+
+	outline.SetAttr(nameTags, "news")
+	outline.SetAttr(nameUnread, "3")
+	outline.SetAttr(nameTags, "news,tech")
+
+	if len(outline.Attributes) != 2 {
+		t.Fatalf("want 2 Attributes, got %d", len(outline.Attributes))
+	}
+
+	got, ok := outline.Attr(nameTags)
+	if !ok {
+		t.Fatalf("want attribute %v", nameTags)
+	}
+	if got != "news,tech" {
+		t.Errorf("want value %q, got %q", "news,tech", got)
+	}
+
+	outline.RemoveAttr(nameTags)
+
+	if _, ok := outline.Attr(nameTags); ok {
+		t.Errorf("want attribute %v to be removed", nameTags)
+	}
+	if len(outline.Attributes) != 1 {
+		t.Errorf("want 1 Attribute, got %d", len(outline.Attributes))
+	}
+}
+
+func TestHeadElement(t *testing.T) {
+	nameDevice := xml.Name{Space: "https://example.org/ns/sync", Local: "device"}
+
+	head := Head{Title: "Feed subscriptions"}
+
+	head.SetElement(nameDevice, "phone")
+	head.SetElement(nameDevice, "desktop")
+
+	if len(head.Elements) != 1 {
+		t.Fatalf("want 1 Element, got %d", len(head.Elements))
+	}
+
+	got, ok := head.Element(nameDevice)
+	if !ok {
+		t.Fatalf("want element %v", nameDevice)
+	}
+	if got != "desktop" {
+		t.Errorf("want value %q, got %q", "desktop", got)
+	}
+
+	head.RemoveElement(nameDevice)
+
+	if _, ok := head.Element(nameDevice); ok {
+		t.Errorf("want element %v to be removed", nameDevice)
+	}
+}
+
+func TestDocumentJSONRoundtrip(t *testing.T) {
+	cases := []struct {
+		tname    string
+		document Document
+	}{
+		{
+			tname:    "spec category",
+			document: specDocumentCategory,
+		},
+		{
+			tname:    "spec directory",
+			document: specDocumentDirectory,
+		},
+		{
+			tname:    "spec places lived",
+			document: specDocumentPlacesLived,
+		},
+		{
+			tname:    "spec subscription list",
+			document: specDocumentSubscriptionList,
+		},
+		{
+			tname:    "extension attributes",
+			document: extensionDocumentAttributes,
+		},
+		{
+			tname:    "extension namespaces",
+			document: extensionDocumentNamespaces,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.tname, func(t *testing.T) {
+			data, err := json.Marshal(&tc.document)
+			if err != nil {
+				t.Fatalf("want no error, got %q", err)
+			}
+
+			var got Document
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatalf("want no error, got %q", err)
+			}
+
+			// The XML element name is not part of the JSON representation
+			want := tc.document
+			want.XMLName = xml.Name{}
+
+			AssertDocumentsEqual(t, got, want)
+		})
+	}
+}
+
+func TestDocumentMarshalXML(t *testing.T) {
+	cases := []struct {
+		tname    string
+		marshal  func(v any) ([]byte, error)
+		document Document
+		options  MarshalOptions
+	}{
+		{
+			tname:    "compact",
+			marshal:  xml.Marshal,
+			document: feedReaderDocumentNewsblur,
+			options:  MarshalOptions{Compact: true, OmitXMLDeclaration: true},
+		},
+		{
+			tname: "indented",
+			marshal: func(v any) ([]byte, error) {
+				return xml.MarshalIndent(v, "", "  ")
+			},
+			document: feedReaderDocumentNewsblur,
+			options:  MarshalOptions{OmitXMLDeclaration: true},
+		},
+		{
+			tname:    "extension namespaces",
+			marshal:  xml.Marshal,
+			document: extensionDocumentNamespaces,
+			options:  MarshalOptions{Compact: true, OmitXMLDeclaration: true},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.tname, func(t *testing.T) {
+			got, err := tc.marshal(&tc.document)
+			if err != nil {
+				t.Fatalf("want no error, got %q", err)
+			}
+
+			want, err := MarshalWithOptions(&tc.document, tc.options)
+			if err != nil {
+				t.Fatalf("want no error, got %q", err)
+			}
+
+			if string(got) != string(want) {
+				t.Errorf("want:\n%s\n\ngot:\n%s", want, got)
+			}
+		})
+	}
+}
+
+func TestOutlineUnmarshalJSON(t *testing.T) {
+	data := `{
+  "text": "The Mets are the best team in baseball.",
+  "categories": "/Philosophy/Baseball/Mets,/Tourism/New York",
+  "created": "Mon, 31 Oct 2005 18:21:33 GMT",
+  "is_comment": true,
+  "attributes": [{"space": "https://example.org/ns/sync", "name": "tags", "value": "sports"}],
+  "outlines": [{"text": "Citi Field", "type": "link", "url": "https://example.org/citi-field.opml"}]
+}`
+
+	want := Outline{
+		Text: "The Mets are the best team in baseball.",
+		Categories: []string{
+			"/Philosophy/Baseball/Mets",
+			"/Tourism/New York",
+		},
+		Created:   mustDecodeRFC1123Time("Mon, 31 Oct 2005 18:21:33 GMT"),
+		IsComment: true,
+		Attributes: []xml.Attr{
+			{Name: xml.Name{Space: "https://example.org/ns/sync", Local: "tags"}, Value: "sports"},
+		},
+		Outlines: []Outline{
+			{Text: "Citi Field", Type: OutlineTypeLink, Url: "https://example.org/citi-field.opml"},
+		},
+	}
+
+	var got Outline
+	if err := json.Unmarshal([]byte(data), &got); err != nil {
+		t.Fatalf("want no error, got %q", err)
+	}
+
+	AssertOutlinesEqual(t, []Outline{got}, []Outline{want})
+}
+
+func TestHeadUnmarshalJSONInvalidDate(t *testing.T) {
+	var head Head
+
+	if err := json.Unmarshal([]byte(`{"title": "Feeds", "date_created": "yesterday"}`), &head); err == nil {
+		t.Error("want error, got none")
+	}
+}
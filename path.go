@@ -0,0 +1,227 @@
+// Copyright (c) VirtualTam
+// SPDX-License-Identifier: MIT
+
+package opml
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	textPathSeparator = '/'
+	textPathEscape    = '\\'
+)
+
+// ErrInvalidPath is returned when parsing a malformed Path or TextPath.
+var ErrInvalidPath = errors.New("opml: invalid path")
+
+// A Path locates an Outline within the Body of a Document, as the list of the
+// indexes of the Outline and of its ancestors, starting from the top-level Outline.
+type Path []int
+
+// ParsePath parses the dot-separated representation of a Path, e.g. "1.0.3".
+func ParsePath(s string) (Path, error) {
+	if s == "" {
+		return nil, fmt.Errorf("%w: empty path", ErrInvalidPath)
+	}
+
+	elements := strings.Split(s, ".")
+	path := make(Path, len(elements))
+
+	for i, element := range elements {
+		index, err := strconv.Atoi(element)
+		if err != nil || index < 0 || strings.HasPrefix(element, "+") || strings.HasPrefix(element, "-") {
+			return nil, fmt.Errorf("%w: %q: invalid index %q", ErrInvalidPath, s, element)
+		}
+
+		path[i] = index
+	}
+
+	return path, nil
+}
+
+// Depth returns the depth of the Outline located by this Path.
+//
+// Top-level Outlines have a depth of 0.
+func (p Path) Depth() int {
+	return len(p) - 1
+}
+
+// String returns the dot-separated representation of this Path, e.g. "1.0.3".
+func (p Path) String() string {
+	indexes := make([]string, len(p))
+
+	for i, index := range p {
+		indexes[i] = strconv.Itoa(index)
+	}
+
+	return strings.Join(indexes, ".")
+}
+
+// clone returns a copy of this Path that does not share its backing array.
+func (p Path) clone() Path {
+	return append(Path(nil), p...)
+}
+
+// child returns a new Path locating the subordinated Outline of p at the given index.
+func (p Path) child(index int) Path {
+	child := make(Path, len(p)+1)
+	copy(child, p)
+	child[len(p)] = index
+
+	return child
+}
+
+// A TextPath locates an Outline within the Body of a Document, as the list of
+// the texts of the Outline and of its ancestors, starting from the top-level Outline.
+type TextPath []string
+
+// ParseTextPath parses the slash-separated representation of a TextPath, e.g.
+// "Programming/Elixir Lang".
+//
+// Slashes and backslashes that are part of an Outline text are escaped with a
+// backslash.
+func ParseTextPath(s string) (TextPath, error) {
+	if s == "" {
+		return nil, fmt.Errorf("%w: empty path", ErrInvalidPath)
+	}
+
+	var (
+		path    TextPath
+		element strings.Builder
+		escaped bool
+	)
+
+	for _, r := range s {
+		switch {
+		case escaped:
+			if r != textPathSeparator && r != textPathEscape {
+				return nil, fmt.Errorf("%w: %q: invalid escape sequence %q", ErrInvalidPath, s, string([]rune{textPathEscape, r}))
+			}
+
+			element.WriteRune(r)
+			escaped = false
+
+		case r == textPathEscape:
+			escaped = true
+
+		case r == textPathSeparator:
+			path = append(path, element.String())
+			element.Reset()
+
+		default:
+			element.WriteRune(r)
+		}
+	}
+
+	if escaped {
+		return nil, fmt.Errorf("%w: %q: unterminated escape sequence", ErrInvalidPath, s)
+	}
+
+	return append(path, element.String()), nil
+}
+
+// String returns the slash-separated representation of this TextPath, e.g.
+// "Programming/Elixir Lang".
+func (p TextPath) String() string {
+	replacer := strings.NewReplacer(
+		string(textPathEscape), string([]rune{textPathEscape, textPathEscape}),
+		string(textPathSeparator), string([]rune{textPathEscape, textPathSeparator}),
+	)
+
+	elements := make([]string, len(p))
+
+	for i, element := range p {
+		elements[i] = replacer.Replace(element)
+	}
+
+	return strings.Join(elements, string(textPathSeparator))
+}
+
+// Get returns the Outline located by the given Path.
+func (d *Document) Get(path Path) (*Outline, bool) {
+	if len(path) == 0 {
+		return nil, false
+	}
+
+	outlines := d.Body.Outlines
+
+	var outline *Outline
+
+	for _, index := range path {
+		if index < 0 || index >= len(outlines) {
+			return nil, false
+		}
+
+		outline = &outlines[index]
+		outlines = outline.Outlines
+	}
+
+	return outline, true
+}
+
+// Find returns the Paths of the Outlines satisfying the predicate, in document order.
+func (d *Document) Find(predicate func(*Outline) bool) []Path {
+	var paths []Path
+
+	for path, outline := range d.All() {
+		if predicate(outline) {
+			paths = append(paths, path)
+		}
+	}
+
+	return paths
+}
+
+// Resolve returns the Path of the Outline located by the given TextPath.
+//
+// When several sibling Outlines have the same text, the first one is selected.
+func (d *Document) Resolve(textPath TextPath) (Path, bool) {
+	if len(textPath) == 0 {
+		return nil, false
+	}
+
+	outlines := d.Body.Outlines
+
+	var path Path
+
+	for _, text := range textPath {
+		index := slices.IndexFunc(outlines, func(o Outline) bool {
+			return o.Text == text
+		})
+		if index < 0 {
+			return nil, false
+		}
+
+		path = append(path, index)
+		outlines = outlines[index].Outlines
+	}
+
+	return path, true
+}
+
+// TextPath returns the TextPath of the Outline located by the given Path.
+func (d *Document) TextPath(path Path) (TextPath, bool) {
+	if len(path) == 0 {
+		return nil, false
+	}
+
+	outlines := d.Body.Outlines
+
+	var textPath TextPath
+
+	for _, index := range path {
+		if index < 0 || index >= len(outlines) {
+			return nil, false
+		}
+
+		textPath = append(textPath, outlines[index].Text)
+		outlines = outlines[index].Outlines
+	}
+
+	return textPath, true
+}
@@ -0,0 +1,298 @@
+// Copyright (c) VirtualTam
+// SPDX-License-Identifier: MIT
+
+package opml
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParsePath(t *testing.T) {
+	cases := []struct {
+		tname   string
+		input   string
+		want    Path
+		wantErr error
+	}{
+		{
+			tname: "top-level",
+			input: "2",
+			want:  Path{2},
+		},
+		{
+			tname: "nested",
+			input: "1.0.3",
+			want:  Path{1, 0, 3},
+		},
+		{
+			tname:   "empty",
+			input:   "",
+			wantErr: ErrInvalidPath,
+		},
+		{
+			tname:   "empty index",
+			input:   "1..3",
+			wantErr: ErrInvalidPath,
+		},
+		{
+			tname:   "negative index",
+			input:   "1.-1",
+			wantErr: ErrInvalidPath,
+		},
+		{
+			tname:   "signed index",
+			input:   "+1",
+			wantErr: ErrInvalidPath,
+		},
+		{
+			tname:   "negative zero index",
+			input:   "1.-0",
+			wantErr: ErrInvalidPath,
+		},
+		{
+			tname:   "not a number",
+			input:   "1.a",
+			wantErr: ErrInvalidPath,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.tname, func(t *testing.T) {
+			got, err := ParsePath(tc.input)
+
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("want error %q, got %q", tc.wantErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("want no error, got %q", err)
+			}
+
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("want %v, got %v", tc.want, got)
+			}
+
+			if got.String() != tc.input {
+				t.Errorf("want String %q, got %q", tc.input, got.String())
+			}
+		})
+	}
+}
+
+func TestParseTextPath(t *testing.T) {
+	cases := []struct {
+		tname   string
+		input   string
+		want    TextPath
+		wantErr error
+	}{
+		{
+			tname: "top-level",
+			input: "Programming",
+			want:  TextPath{"Programming"},
+		},
+		{
+			tname: "nested",
+			input: "Programming/Elixir Lang",
+			want:  TextPath{"Programming", "Elixir Lang"},
+		},
+		{
+			tname: "escaped separator",
+			input: `News/AC\/DC fans`,
+			want:  TextPath{"News", "AC/DC fans"},
+		},
+		{
+			tname: "escaped escape",
+			input: `Windows/C:\\`,
+			want:  TextPath{"Windows", `C:\`},
+		},
+		{
+			tname: "empty text",
+			input: "News/",
+			want:  TextPath{"News", ""},
+		},
+		{
+			tname:   "empty",
+			input:   "",
+			wantErr: ErrInvalidPath,
+		},
+		{
+			tname:   "invalid escape sequence",
+			input:   `News\n`,
+			wantErr: ErrInvalidPath,
+		},
+		{
+			tname:   "unterminated escape sequence",
+			input:   `News\`,
+			wantErr: ErrInvalidPath,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.tname, func(t *testing.T) {
+			got, err := ParseTextPath(tc.input)
+
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("want error %q, got %q", tc.wantErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("want no error, got %q", err)
+			}
+
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("want %q, got %q", tc.want, got)
+			}
+
+			if got.String() != tc.input {
+				t.Errorf("want String %q, got %q", tc.input, got.String())
+			}
+		})
+	}
+}
+
+func TestDocumentGet(t *testing.T) {
+	cases := []struct {
+		tname    string
+		path     Path
+		wantText string
+		wantOk   bool
+	}{
+		{
+			tname:    "top-level",
+			path:     Path{1},
+			wantText: "News",
+			wantOk:   true,
+		},
+		{
+			tname:    "nested",
+			path:     Path{0, 0, 0},
+			wantText: "Elixir Lang",
+			wantOk:   true,
+		},
+		{
+			tname: "empty",
+			path:  Path{},
+		},
+		{
+			tname: "out of range",
+			path:  Path{0, 2},
+		},
+		{
+			tname: "negative",
+			path:  Path{-1},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.tname, func(t *testing.T) {
+			got, ok := walkDocument.Get(tc.path)
+			if ok != tc.wantOk {
+				t.Fatalf("want ok %t, got %t", tc.wantOk, ok)
+			}
+
+			if !ok {
+				return
+			}
+
+			if got.Text != tc.wantText {
+				t.Errorf("want Text %q, got %q", tc.wantText, got.Text)
+			}
+		})
+	}
+}
+
+func TestDocumentFind(t *testing.T) {
+	got := walkDocument.Find(func(o *Outline) bool {
+		return strings.HasPrefix(o.Text, "Elixir") || o.Text == "Lobsters"
+	})
+
+	want := []Path{{0, 0}, {0, 0, 0}, {1, 0}}
+
+	if !slices.EqualFunc(got, want, slices.Equal) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func TestDocumentResolve(t *testing.T) {
+	document := Document{
+		Body: Body{
+			Outlines: []Outline{
+				{
+					Text: "Programming",
+					Outlines: []Outline{
+						{Text: "Go"},
+						{Text: "Elixir Lang"},
+						{Text: "Elixir Lang", Url: "https://elixir-lang.org/"},
+					},
+				},
+			},
+		},
+	}
+
+	cases := []struct {
+		tname    string
+		textPath string
+		want     Path
+		wantOk   bool
+	}{
+		{
+			tname:    "top-level",
+			textPath: "Programming",
+			want:     Path{0},
+			wantOk:   true,
+		},
+		{
+			tname:    "nested, first of duplicates",
+			textPath: "Programming/Elixir Lang",
+			want:     Path{0, 1},
+			wantOk:   true,
+		},
+		{
+			tname:    "not found",
+			textPath: "Programming/Rust",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.tname, func(t *testing.T) {
+			textPath, err := ParseTextPath(tc.textPath)
+			if err != nil {
+				t.Fatalf("want no error, got %q", err)
+			}
+
+			got, ok := document.Resolve(textPath)
+			if ok != tc.wantOk {
+				t.Fatalf("want ok %t, got %t", tc.wantOk, ok)
+			}
+
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("want %v, got %v", tc.want, got)
+			}
+
+			if !ok {
+				return
+			}
+
+			gotTextPath, ok := document.TextPath(got)
+			if !ok {
+				t.Fatalf("want ok, got not ok")
+			}
+
+			if gotTextPath.String() != tc.textPath {
+				t.Errorf("want TextPath %q, got %q", tc.textPath, gotTextPath)
+			}
+		})
+	}
+}
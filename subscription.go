@@ -0,0 +1,134 @@
+// Copyright (c) VirtualTam
+// SPDX-License-Identifier: MIT
+
+package opml
+
+import (
+	"slices"
+)
+
+// A Subscription is a feed listed in a subscription list, along with the
+// folder it belongs to.
+type Subscription struct {
+	// The Text of the subscription Outline.
+	Text string `json:"text"`
+
+	// The top-level title from the feed.
+	Title string `json:"title,omitempty"`
+
+	// The address of the feed.
+	XmlUrl string `json:"xml_url"`
+
+	// The top-level link element from the feed.
+	HtmlUrl string `json:"html_url,omitempty"`
+
+	// Version of RSS/Atom that is being supplied by the feed.
+	Version RSSVersion `json:"version,omitempty"`
+
+	// The top-level language element from the feed.
+	Language string `json:"language,omitempty"`
+
+	// The top-level description element from the feed.
+	Description string `json:"description,omitempty"`
+
+	// The texts of the directory Outlines containing the subscription, starting
+	// from the top-level Outline; empty if the subscription is a top-level Outline.
+	Folder TextPath `json:"folder,omitempty"`
+
+	// A list of category strings.
+	Categories []string `json:"categories,omitempty"`
+
+	// The Path of the subscription Outline in the Document it was extracted from.
+	Path Path `json:"path,omitempty"`
+}
+
+// Subscriptions returns the subscription Outlines of the Document, in document order.
+func (d *Document) Subscriptions() []Subscription {
+	var subscriptions []Subscription
+
+	for path, outline := range d.All() {
+		if outline.OutlineType() != OutlineTypeSubscription {
+			continue
+		}
+
+		folder, _ := d.TextPath(path[:len(path)-1])
+
+		subscriptions = append(subscriptions, Subscription{
+			Text:        outline.Text,
+			Title:       outline.Title,
+			XmlUrl:      outline.XmlUrl,
+			HtmlUrl:     outline.HtmlUrl,
+			Version:     outline.Version,
+			Language:    outline.Language,
+			Description: outline.Description,
+			Folder:      folder,
+			Categories:  slices.Clone(outline.Categories),
+			Path:        path,
+		})
+	}
+
+	return subscriptions
+}
+
+// Outline returns the subscription Outline corresponding to this Subscription.
+//
+// If the Subscription has no Text, the Outline Text is set to its Title, or to
+// its XmlUrl if it has no Title.
+func (s *Subscription) Outline() Outline {
+	text := s.Text
+	if text == "" {
+		text = s.Title
+	}
+	if text == "" {
+		text = s.XmlUrl
+	}
+
+	return Outline{
+		Text:        text,
+		Type:        OutlineTypeSubscription,
+		Title:       s.Title,
+		XmlUrl:      s.XmlUrl,
+		HtmlUrl:     s.HtmlUrl,
+		Version:     s.Version,
+		Language:    s.Language,
+		Description: s.Description,
+		Categories:  slices.Clone(s.Categories),
+	}
+}
+
+// NewSubscriptionDocument returns a subscription list Document with the given
+// title, where subscriptions are grouped in directory Outlines by folder.
+//
+// Folders and subscriptions are listed in the order they first appear.
+func NewSubscriptionDocument(title string, subscriptions []Subscription) *Document {
+	document := &Document{
+		Version: Version2,
+		Head: Head{
+			Title: title,
+		},
+	}
+
+	for _, subscription := range subscriptions {
+		outlines := &document.Body.Outlines
+
+		for _, folder := range subscription.Folder {
+			index := slices.IndexFunc(*outlines, func(o Outline) bool {
+				return o.Text == folder && o.OutlineType() != OutlineTypeSubscription
+			})
+
+			if index < 0 {
+				*outlines = append(*outlines, Outline{
+					Text:  folder,
+					Title: folder,
+				})
+				index = len(*outlines) - 1
+			}
+
+			outlines = &(*outlines)[index].Outlines
+		}
+
+		*outlines = append(*outlines, subscription.Outline())
+	}
+
+	return document
+}
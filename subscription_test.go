@@ -0,0 +1,143 @@
+// Copyright (c) VirtualTam
+// SPDX-License-Identifier: MIT
+
+package opml
+
+import (
+	"encoding/xml"
+	"slices"
+	"testing"
+)
+
+func TestDocumentSubscriptions(t *testing.T) {
+	document := Document{
+		Body: Body{
+			Outlines: []Outline{
+				{
+					Text:    "Lobsters",
+					Type:    OutlineTypeSubscription,
+					Version: RSSVersion2,
+					XmlUrl:  "https://lobste.rs/rss",
+				},
+				{
+					Text: "Programming",
+					Outlines: []Outline{
+						{Text: "A note"},
+						{
+							Text: "Go",
+							Outlines: []Outline{
+								{
+									Text:        "The Go Blog",
+									Title:       "The Go Programming Language Blog",
+									Type:        OutlineTypeSubscription,
+									HtmlUrl:     "https://go.dev/blog",
+									XmlUrl:      "https://go.dev/blog/feed.atom",
+									Language:    "en",
+									Description: "Official blog",
+									Categories:  []string{"/Programming/Go", "golang"},
+								},
+							},
+						},
+					},
+				},
+				{
+					Text: "Links",
+					Type: OutlineTypeLink,
+					Url:  "https://example.org/links.opml",
+				},
+			},
+		},
+	}
+
+	got := document.Subscriptions()
+
+	want := []Subscription{
+		{
+			Text:    "Lobsters",
+			Version: RSSVersion2,
+			XmlUrl:  "https://lobste.rs/rss",
+			Path:    Path{0},
+		},
+		{
+			Text:        "The Go Blog",
+			Title:       "The Go Programming Language Blog",
+			HtmlUrl:     "https://go.dev/blog",
+			XmlUrl:      "https://go.dev/blog/feed.atom",
+			Language:    "en",
+			Description: "Official blog",
+			Folder:      TextPath{"Programming", "Go"},
+			Categories:  []string{"/Programming/Go", "golang"},
+			Path:        Path{1, 1, 0},
+		},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("want %d Subscriptions, got %d", len(want), len(got))
+	}
+
+	for index, wantSubscription := range want {
+		gotSubscription := got[index]
+
+		if gotSubscription.Text != wantSubscription.Text ||
+			gotSubscription.Title != wantSubscription.Title ||
+			gotSubscription.XmlUrl != wantSubscription.XmlUrl ||
+			gotSubscription.HtmlUrl != wantSubscription.HtmlUrl ||
+			gotSubscription.Version != wantSubscription.Version ||
+			gotSubscription.Language != wantSubscription.Language ||
+			gotSubscription.Description != wantSubscription.Description ||
+			!slices.Equal(gotSubscription.Folder, wantSubscription.Folder) ||
+			!slices.Equal(gotSubscription.Categories, wantSubscription.Categories) ||
+			!slices.Equal(gotSubscription.Path, wantSubscription.Path) {
+			t.Errorf("want Subscription %d %+v, got %+v", index, wantSubscription, gotSubscription)
+		}
+	}
+}
+
+func TestNewSubscriptionDocument(t *testing.T) {
+	subscriptions := feedReaderDocumentFeedly.Subscriptions()
+
+	got := NewSubscriptionDocument(feedReaderDocumentFeedly.Head.Title, subscriptions)
+
+	if got.Head.Title != feedReaderDocumentFeedly.Head.Title {
+		t.Errorf("want Head > Title %q, got %q", feedReaderDocumentFeedly.Head.Title, got.Head.Title)
+	}
+
+	want := feedReaderDocumentFeedly
+	want.XMLName = xml.Name{}
+	want.Version = Version2
+
+	AssertDocumentsEqual(t, *got, want)
+}
+
+func TestNewSubscriptionDocumentGrouping(t *testing.T) {
+	subscriptions := []Subscription{
+		{Title: "Lobsters", XmlUrl: "https://lobste.rs/rss", Folder: TextPath{"News"}},
+		{XmlUrl: "https://go.dev/blog/feed.atom", Folder: TextPath{"Programming", "Go"}},
+		{Text: "Hacker News", XmlUrl: "https://news.ycombinator.com/rss", Folder: TextPath{"News"}},
+		{Text: "xkcd", XmlUrl: "https://xkcd.com/atom.xml"},
+		{Text: "Elixir Lang", XmlUrl: "https://feeds.feedburner.com/ElixirLang", Folder: TextPath{"Programming"}},
+	}
+
+	document := NewSubscriptionDocument("Subscriptions", subscriptions)
+
+	got := collectOutlines(document.All())
+
+	want := []string{
+		"0 News",
+		"0.0 Lobsters",
+		"0.1 Hacker News",
+		"1 Programming",
+		"1.0 Go",
+		"1.0.0 https://go.dev/blog/feed.atom",
+		"1.1 Elixir Lang",
+		"2 xkcd",
+	}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("want %q, got %q", want, got)
+	}
+
+	if diagnostics := Validate(document); len(diagnostics) > 0 {
+		t.Errorf("want no diagnostics, got %v", diagnostics)
+	}
+}
@@ -0,0 +1,634 @@
+// Copyright (c) VirtualTam
+// SPDX-License-Identifier: MIT
+
+package opml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// A ConflictType describes why the changes made to an Outline by two Documents
+// could not be merged.
+type ConflictType string
+
+const (
+	// ConflictAttribute indicates both Documents changed the same attribute to
+	// different values.
+	ConflictAttribute ConflictType = "attribute"
+
+	// ConflictParent indicates both Documents moved the same Outline to
+	// different parents.
+	ConflictParent ConflictType = "parent"
+
+	// ConflictOursRemoved indicates our Document removed an Outline that their
+	// Document changed.
+	ConflictOursRemoved ConflictType = "ours-removed"
+
+	// ConflictTheirsRemoved indicates their Document removed an Outline that our
+	// Document changed, or that still has subordinated Outlines.
+	ConflictTheirsRemoved ConflictType = "theirs-removed"
+)
+
+// A Conflict describes changes to an Outline, or to the Head, that could not be
+// merged by ThreeWayMerge.
+type Conflict struct {
+	// The ConflictType.
+	Type ConflictType `json:"type"`
+
+	// The Text of the Outline in the merged Document; empty for Head conflicts.
+	Text string `json:"text,omitempty"`
+
+	// The Path of the Outline in the merged Document; nil for Head conflicts.
+	Path Path `json:"path,omitempty"`
+
+	// The name of the conflicting attribute or Head element, for ConflictAttribute.
+	Name string `json:"name,omitempty"`
+
+	// The conflicting values in the base, our and their Documents: attribute
+	// values for ConflictAttribute, parent text paths for ConflictParent.
+	Base   string `json:"base,omitempty"`
+	Ours   string `json:"ours,omitempty"`
+	Theirs string `json:"theirs,omitempty"`
+
+	// The matching key of the Outline, to locate it in the merged Document.
+	key string
+}
+
+func (c Conflict) String() string {
+	subject := "head"
+	if c.Path != nil {
+		subject = fmt.Sprintf("outline %s %q", c.Path, c.Text)
+	}
+
+	switch c.Type {
+	case ConflictAttribute:
+		return fmt.Sprintf("%s: %s: %s: base %q, ours %q, theirs %q", c.Type, subject, c.Name, c.Base, c.Ours, c.Theirs)
+	case ConflictParent:
+		return fmt.Sprintf("%s: %s: base %q, ours %q, theirs %q", c.Type, subject, c.Base, c.Ours, c.Theirs)
+	}
+
+	return fmt.Sprintf("%s: %s", c.Type, subject)
+}
+
+// Conflicts is a list of Conflict.
+type Conflicts []Conflict
+
+// String returns the text representation of the Conflicts, with one Conflict per line.
+func (cs Conflicts) String() string {
+	var sb strings.Builder
+
+	for _, conflict := range cs {
+		sb.WriteString(conflict.String())
+		sb.WriteByte('\n')
+	}
+
+	return sb.String()
+}
+
+// ThreeWayMerge merges the changes made to a base Document by our Document and
+// their Document, and returns the merged Document with the Conflicts between
+// both sets of changes.
+//
+// Outlines are matched by their normalized xmlUrl or url attribute when they
+// have one, and by TextPath otherwise.
+//
+// The merged Document starts as a copy of our Document, to which the changes
+// of their Document are applied:
+//   - added Outlines are inserted under the same parent, after the same sibling;
+//   - removed Outlines are removed, unless our Document changed them or they
+//     still have subordinated Outlines;
+//   - moved Outlines are moved, unless our Document moved them elsewhere;
+//   - changed attributes are set, unless our Document changed them differently.
+//
+// Outlines removed by our Document but changed by their Document are restored,
+// so that no subscription is lost. Our changes are kept for all other Conflicts.
+//
+// The metadata and extension elements of the Head are merged in the same way.
+// The expansion state, scroll state and window position describe how our
+// Document is displayed, and are kept from it.
+func ThreeWayMerge(base, ours, theirs *Document) (*Document, Conflicts, error) {
+	m := &threeWayMerger{
+		base:   indexOutlines(base),
+		ours:   indexOutlines(ours),
+		theirs: indexOutlines(theirs),
+		result: cloneDocument(ours),
+		paths:  make(map[string]Path),
+	}
+
+	for _, entry := range m.ours.entries {
+		m.paths[entry.key] = entry.path
+	}
+
+	m.mergeHead(&base.Head, &ours.Head, &theirs.Head)
+
+	for _, namespace := range theirs.Namespaces {
+		if !slices.Contains(m.result.Namespaces, namespace) {
+			m.result.Namespaces = append(m.result.Namespaces, namespace)
+		}
+	}
+
+	for _, entry := range m.theirs.entries {
+		if err := m.mergeTheirs(entry); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := m.removeTheirs(); err != nil {
+		return nil, nil, err
+	}
+
+	// Locate conflicting Outlines once all changes have been applied
+	for i, conflict := range m.conflicts {
+		if path, ok := m.locate(conflict.key); ok {
+			outline, _ := m.result.Get(path)
+
+			m.conflicts[i].Path = path.clone()
+			m.conflicts[i].Text = outline.Text
+		}
+	}
+
+	return m.result, m.conflicts, nil
+}
+
+type threeWayMerger struct {
+	base   outlineIndex
+	ours   outlineIndex
+	theirs outlineIndex
+
+	result    *Document
+	conflicts Conflicts
+
+	// The Paths of the Outlines of the merged Document by matching key, updated
+	// as changes are applied.
+	paths map[string]Path
+}
+
+func (m *threeWayMerger) mergeHead(base, ours, theirs *Head) {
+	merged := &m.result.Head
+
+	merge := func(name string, value *string, base, ours, theirs string) {
+		var conflict bool
+
+		*value, conflict = mergeValue(base, ours, theirs)
+		if conflict {
+			m.conflicts = append(m.conflicts, Conflict{
+				Type:   ConflictAttribute,
+				Name:   name,
+				Base:   base,
+				Ours:   ours,
+				Theirs: theirs,
+			})
+		}
+	}
+
+	merge("title", &merged.Title, base.Title, ours.Title, theirs.Title)
+	merge("ownerName", &merged.OwnerName, base.OwnerName, ours.OwnerName, theirs.OwnerName)
+	merge("ownerEmail", &merged.OwnerEmail, base.OwnerEmail, ours.OwnerEmail, theirs.OwnerEmail)
+	merge("ownerId", &merged.OwnerId, base.OwnerId, ours.OwnerId, theirs.OwnerId)
+	merge("docs", &merged.Docs, base.Docs, ours.Docs, theirs.Docs)
+
+	encodeDate := func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+
+		return encodeRFC1123Time(t)
+	}
+
+	switch {
+	case theirs.DateCreated.Equal(ours.DateCreated), theirs.DateCreated.Equal(base.DateCreated):
+	case ours.DateCreated.Equal(base.DateCreated):
+		merged.DateCreated = theirs.DateCreated
+		merged.dateCreatedSource = theirs.dateCreatedSource
+	default:
+		m.conflicts = append(m.conflicts, Conflict{
+			Type:   ConflictAttribute,
+			Name:   "dateCreated",
+			Base:   encodeDate(base.DateCreated),
+			Ours:   encodeDate(ours.DateCreated),
+			Theirs: encodeDate(theirs.DateCreated),
+		})
+	}
+
+	if theirs.DateModified.After(merged.DateModified) {
+		merged.DateModified = theirs.DateModified
+		merged.dateModifiedSource = theirs.dateModifiedSource
+	}
+
+	var names []xml.Name
+
+	for _, element := range slices.Concat(ours.Elements, theirs.Elements) {
+		if !slices.Contains(names, element.Name) {
+			names = append(names, element.Name)
+		}
+	}
+
+	for _, name := range names {
+		baseValue, _ := base.Element(name)
+		oursValue, _ := ours.Element(name)
+		theirsValue, inTheirs := theirs.Element(name)
+
+		var value string
+		merge(attrName(name), &value, baseValue, oursValue, theirsValue)
+
+		switch {
+		case value == oursValue:
+		case inTheirs:
+			merged.SetElement(name, value)
+		default:
+			merged.RemoveElement(name)
+		}
+	}
+}
+
+// mergeTheirs applies the addition, move and attribute changes of an Outline
+// of their Document to the merged Document.
+func (m *threeWayMerger) mergeTheirs(entry outlineEntry) error {
+	baseEntry, inBase := m.base.entry(entry.key)
+	oursEntry, inOurs := m.ours.entry(entry.key)
+
+	switch {
+	case !inOurs && inBase:
+		if !changedEntry(baseEntry, entry) {
+			return nil
+		}
+
+		m.conflicts = append(m.conflicts, Conflict{Type: ConflictOursRemoved, key: entry.key})
+
+		return m.add(entry)
+
+	case !inOurs:
+		// Added directories are created along with their first subordinated Outline
+		if entry.outline.XmlUrl == "" && entry.outline.Url == "" && len(entry.outline.Outlines) > 0 {
+			return nil
+		}
+
+		return m.add(entry)
+	}
+
+	var baseAttrs []xml.Attr
+	if inBase {
+		baseAttrs = outlineAttrs(baseEntry.outline)
+	}
+
+	oursAttrs := outlineAttrs(oursEntry.outline)
+
+	attrs, conflicts := mergeAttributes(baseAttrs, oursAttrs, outlineAttrs(entry.outline))
+
+	for _, conflict := range conflicts {
+		conflict.key = entry.key
+		m.conflicts = append(m.conflicts, conflict)
+	}
+
+	path, ok := m.locate(entry.key)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrOutlineNotFound, entry.path)
+	}
+
+	if !slices.Equal(attrs, oursAttrs) {
+		if err := m.setAttributes(path, attrs); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case slices.Equal(oursEntry.parent, entry.parent):
+		return nil
+	case inBase && slices.Equal(oursEntry.parent, baseEntry.parent):
+		return m.move(entry)
+	case inBase && slices.Equal(entry.parent, baseEntry.parent):
+		return nil
+	}
+
+	conflict := Conflict{
+		Type:   ConflictParent,
+		Ours:   oursEntry.parent.String(),
+		Theirs: entry.parent.String(),
+		key:    entry.key,
+	}
+	if inBase {
+		conflict.Base = baseEntry.parent.String()
+	}
+
+	m.conflicts = append(m.conflicts, conflict)
+
+	return nil
+}
+
+// removeTheirs removes the Outlines that their Document removed from the merged
+// Document, in reverse document order.
+func (m *threeWayMerger) removeTheirs() error {
+	var keys []string
+
+	for _, entry := range m.ours.entries {
+		if _, ok := m.theirs.entry(entry.key); ok {
+			continue
+		}
+
+		baseEntry, inBase := m.base.entry(entry.key)
+		if !inBase {
+			continue
+		}
+
+		if changedEntry(baseEntry, entry) {
+			m.conflicts = append(m.conflicts, Conflict{Type: ConflictTheirsRemoved, key: entry.key})
+			continue
+		}
+
+		keys = append(keys, entry.key)
+	}
+
+	var removed []outlineEntry
+
+	for _, key := range keys {
+		if path, ok := m.locate(key); ok {
+			removed = append(removed, outlineEntry{key: key, path: path})
+		}
+	}
+
+	slices.SortFunc(removed, func(a, b outlineEntry) int {
+		return slices.Compare(a.path, b.path)
+	})
+
+	for _, entry := range slices.Backward(removed) {
+		if outline, _ := m.result.Get(entry.path); len(outline.Outlines) > 0 {
+			m.conflicts = append(m.conflicts, Conflict{Type: ConflictTheirsRemoved, key: entry.key})
+			continue
+		}
+
+		if _, err := m.result.Remove(entry.path); err != nil {
+			return err
+		}
+
+		delete(m.paths, entry.key)
+		m.shiftPaths(entry.path, -1)
+	}
+
+	return nil
+}
+
+// add inserts a copy of an Outline of their Document in the merged Document,
+// without its subordinated Outlines.
+func (m *threeWayMerger) add(entry outlineEntry) error {
+	parent, err := m.ensureParent(entry.path[:len(entry.path)-1])
+	if err != nil {
+		return err
+	}
+
+	path := m.insertPath(parent, entry.path)
+
+	if err := m.result.Insert(path, cloneOutlineAttributes(*entry.outline)); err != nil {
+		return err
+	}
+
+	m.shiftPaths(path, 1)
+	m.paths[entry.key] = path
+
+	return nil
+}
+
+// move moves an Outline of the merged Document to the parent it has in their
+// Document.
+func (m *threeWayMerger) move(entry outlineEntry) error {
+	parent, err := m.ensureParent(entry.path[:len(entry.path)-1])
+	if err != nil {
+		return err
+	}
+
+	// Locate the Outline once its parent exists, as adding the parent may shift it
+	path, ok := m.locate(entry.key)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrOutlineNotFound, entry.path)
+	}
+
+	to := m.insertPath(parent, entry.path)
+
+	if err := m.result.Move(path, to); err != nil {
+		return err
+	}
+
+	// Locate the moved Outlines as Move does, once they are removed
+	moved := make(map[string]Path)
+
+	for key, p := range m.paths {
+		if len(p) >= len(path) && slices.Equal(p[:len(path)], path) {
+			moved[key] = p[len(path):]
+			delete(m.paths, key)
+		}
+	}
+
+	m.shiftPaths(path, -1)
+
+	if depth := len(path) - 1; len(to) > depth &&
+		slices.Equal(to[:depth], path[:depth]) && to[depth] > path[depth] {
+		to[depth]--
+	}
+
+	m.shiftPaths(to, 1)
+
+	for key, suffix := range moved {
+		m.paths[key] = slices.Concat(to, suffix)
+	}
+
+	return nil
+}
+
+// ensureParent returns the Path in the merged Document of the Outline located
+// at the given Path of their Document, adding it and its ancestors if needed.
+func (m *threeWayMerger) ensureParent(theirsPath Path) (Path, error) {
+	if len(theirsPath) == 0 {
+		return Path{}, nil
+	}
+
+	entry, ok := m.theirs.at(theirsPath)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrOutlineNotFound, theirsPath)
+	}
+
+	if path, ok := m.locate(entry.key); ok {
+		return path, nil
+	}
+
+	if err := m.add(entry); err != nil {
+		return nil, err
+	}
+
+	path, _ := m.locate(entry.key)
+
+	return path, nil
+}
+
+// insertPath returns the Path of the merged Document where to insert an
+// Outline located at the given Path of their Document, under parent.
+//
+// The Outline is inserted after the sibling preceding it in their Document if
+// the merged Document has this sibling under the same parent, first if it has
+// no preceding sibling, and last otherwise.
+func (m *threeWayMerger) insertPath(parent Path, theirsPath Path) Path {
+	index := theirsPath[len(theirsPath)-1]
+
+	if index > 0 {
+		siblingPath := append(theirsPath[:len(theirsPath)-1].clone(), index-1)
+
+		index = len(m.outlines(parent))
+
+		if sibling, ok := m.theirs.at(siblingPath); ok {
+			if path, ok := m.locate(sibling.key); ok && slices.Equal(path[:len(path)-1], parent) {
+				index = path[len(path)-1] + 1
+			}
+		}
+	}
+
+	return append(parent.clone(), index)
+}
+
+// setAttributes sets the attributes of the Outline of the merged Document
+// located at path, keeping its subordinated Outlines.
+func (m *threeWayMerger) setAttributes(path Path, attrs []xml.Attr) error {
+	var mOutline marshalableOutline
+	if err := mOutline.setXMLAttrs(attrs, nil); err != nil {
+		return err
+	}
+
+	merged, err := mOutline.toOutline(nil)
+	if err != nil {
+		return err
+	}
+
+	outline, _ := m.result.Get(path)
+
+	merged.Outlines = outline.Outlines
+	merged.Position = outline.Position
+
+	*outline = merged
+
+	return nil
+}
+
+// outlines returns the subordinated Outlines of the Outline of the merged
+// Document located at parent, or its top-level Outlines if parent is empty.
+func (m *threeWayMerger) outlines(parent Path) []Outline {
+	if len(parent) == 0 {
+		return m.result.Body.Outlines
+	}
+
+	outline, _ := m.result.Get(parent)
+
+	return outline.Outlines
+}
+
+// locate returns the Path of the Outline of the merged Document with the
+// given matching key.
+func (m *threeWayMerger) locate(key string) (Path, bool) {
+	path, ok := m.paths[key]
+
+	return path, ok
+}
+
+// shiftPaths shifts the Paths of the Outlines of the merged Document located
+// at or after the given Path, and of their subordinated Outlines, by delta
+// positions under the same parent.
+func (m *threeWayMerger) shiftPaths(at Path, delta int) {
+	depth := len(at) - 1
+
+	for key, path := range m.paths {
+		if len(path) <= depth || path[depth] < at[depth] || !slices.Equal(path[:depth], at[:depth]) {
+			continue
+		}
+
+		shifted := path.clone()
+		shifted[depth] += delta
+
+		m.paths[key] = shifted
+	}
+}
+
+// changedEntry returns whether an Outline was moved or has different attributes.
+func changedEntry(a, b outlineEntry) bool {
+	return !slices.Equal(a.parent, b.parent) || len(diffAttributes(a.outline, b.outline)) > 0
+}
+
+// mergeAttributes returns the three-way merge of the attributes of an Outline,
+// in the order of our attributes followed by their added attributes, and the
+// Conflicts for attributes changed differently by both sides.
+func mergeAttributes(base, ours, theirs []xml.Attr) ([]xml.Attr, Conflicts) {
+	var names []xml.Name
+
+	for _, attr := range slices.Concat(ours, theirs) {
+		if !slices.Contains(names, attr.Name) {
+			names = append(names, attr.Name)
+		}
+	}
+
+	var (
+		attrs     []xml.Attr
+		conflicts Conflicts
+	)
+
+	for _, name := range names {
+		baseValue := attrValue(base, name)
+		oursValue := attrValue(ours, name)
+		theirsValue := attrValue(theirs, name)
+
+		value, conflict := mergeValue(baseValue, oursValue, theirsValue)
+		if conflict {
+			conflicts = append(conflicts, Conflict{
+				Type:   ConflictAttribute,
+				Name:   attrName(name),
+				Base:   baseValue,
+				Ours:   oursValue,
+				Theirs: theirsValue,
+			})
+		}
+
+		if value != "" || name.Local == "text" {
+			attrs = append(attrs, xml.Attr{Name: name, Value: value})
+		}
+	}
+
+	return attrs, conflicts
+}
+
+// mergeValue returns the three-way merge of a value, and whether both sides
+// changed it differently, in which case our value is kept.
+func mergeValue(base, ours, theirs string) (string, bool) {
+	switch {
+	case ours == theirs, theirs == base:
+		return ours, false
+	case ours == base:
+		return theirs, false
+	}
+
+	return ours, true
+}
+
+// cloneDocument returns a deep copy of a Document.
+func cloneDocument(d *Document) *Document {
+	clone := *d
+
+	clone.Namespaces = slices.Clone(d.Namespaces)
+	clone.Head.ExpansionState = slices.Clone(d.Head.ExpansionState)
+	clone.Head.Elements = slices.Clone(d.Head.Elements)
+	clone.Body.Outlines = cloneOutlines(d.Body.Outlines)
+
+	return &clone
+}
+
+func cloneOutlines(outlines []Outline) []Outline {
+	if outlines == nil {
+		return nil
+	}
+
+	clones := make([]Outline, len(outlines))
+
+	for i, outline := range outlines {
+		clones[i] = cloneOutlineAttributes(outline)
+		clones[i].Outlines = cloneOutlines(outline.Outlines)
+	}
+
+	return clones
+}
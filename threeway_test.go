@@ -0,0 +1,358 @@
+// Copyright (c) VirtualTam
+// SPDX-License-Identifier: MIT
+
+package opml
+
+import (
+	"encoding/xml"
+	"slices"
+	"testing"
+)
+
+// newSyncDocument returns the base Document of a subscription list
+// synchronized between devices.
+func newSyncDocument() *Document {
+	return &Document{
+		Version: Version2,
+		Head: Head{
+			Title: "Subscriptions",
+		},
+		Body: Body{
+			Outlines: []Outline{
+				{
+					Text: "Programming",
+					Outlines: []Outline{
+						{Text: "Elixir Lang", Type: OutlineTypeSubscription, XmlUrl: "https://feeds.feedburner.com/ElixirLang"},
+						{Text: "Go", Type: OutlineTypeSubscription, XmlUrl: "https://go.dev/blog/feed.atom"},
+					},
+				},
+				{
+					Text: "News",
+					Outlines: []Outline{
+						{Text: "Lobsters", Type: OutlineTypeSubscription, XmlUrl: "https://lobste.rs/rss"},
+						{Text: "Hacker News", Type: OutlineTypeSubscription, XmlUrl: "https://news.ycombinator.com/rss"},
+					},
+				},
+				{
+					Text: "Unsorted",
+					Outlines: []Outline{
+						{Text: "Zephyr", Type: OutlineTypeSubscription, XmlUrl: "https://www.zephyrproject.org/feed/"},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestThreeWayMerge(t *testing.T) {
+	rust := Outline{Text: "Rust", Type: OutlineTypeSubscription, XmlUrl: "https://blog.rust-lang.org/feed.xml"}
+
+	setText := func(path Path, text string) func(*Document) error {
+		return func(d *Document) error {
+			outline, ok := d.Get(path)
+			if !ok {
+				return ErrOutlineNotFound
+			}
+
+			outline.Text = text
+
+			return nil
+		}
+	}
+
+	cases := []struct {
+		tname         string
+		ours          func(*Document) error
+		theirs        func(*Document) error
+		want          []Outline
+		wantConflicts string
+	}{
+		{
+			tname: "no changes",
+			want:  newSyncDocument().Body.Outlines,
+		},
+		{
+			tname: "non-conflicting changes",
+			ours: func(d *Document) error {
+				lobsters, _ := d.Get(Path{1, 0})
+				lobsters.HtmlUrl = "https://lobste.rs/"
+
+				return d.Insert(Path{0, 2}, rust)
+			},
+			theirs: func(d *Document) error {
+				if _, err := d.Remove(Path{1, 1}); err != nil {
+					return err
+				}
+
+				if err := d.Move(Path{2, 0}, Path{0, 2}); err != nil {
+					return err
+				}
+
+				return setText(Path{0, 1}, "The Go Blog")(d)
+			},
+			want: []Outline{
+				{
+					Text: "Programming",
+					Outlines: []Outline{
+						{Text: "Elixir Lang", Type: OutlineTypeSubscription, XmlUrl: "https://feeds.feedburner.com/ElixirLang"},
+						{Text: "The Go Blog", Type: OutlineTypeSubscription, XmlUrl: "https://go.dev/blog/feed.atom"},
+						{Text: "Zephyr", Type: OutlineTypeSubscription, XmlUrl: "https://www.zephyrproject.org/feed/"},
+						rust,
+					},
+				},
+				{
+					Text: "News",
+					Outlines: []Outline{
+						{Text: "Lobsters", Type: OutlineTypeSubscription, HtmlUrl: "https://lobste.rs/", XmlUrl: "https://lobste.rs/rss"},
+					},
+				},
+				{
+					Text: "Unsorted",
+				},
+			},
+		},
+		{
+			tname: "folder renamed",
+			ours: func(d *Document) error {
+				return d.Insert(Path{0, 2}, rust)
+			},
+			theirs: setText(Path{1}, "Links"),
+			want: []Outline{
+				{
+					Text: "Programming",
+					Outlines: []Outline{
+						{Text: "Elixir Lang", Type: OutlineTypeSubscription, XmlUrl: "https://feeds.feedburner.com/ElixirLang"},
+						{Text: "Go", Type: OutlineTypeSubscription, XmlUrl: "https://go.dev/blog/feed.atom"},
+						rust,
+					},
+				},
+				{
+					Text: "Links",
+					Outlines: []Outline{
+						{Text: "Lobsters", Type: OutlineTypeSubscription, XmlUrl: "https://lobste.rs/rss"},
+						{Text: "Hacker News", Type: OutlineTypeSubscription, XmlUrl: "https://news.ycombinator.com/rss"},
+					},
+				},
+				{
+					Text: "Unsorted",
+					Outlines: []Outline{
+						{Text: "Zephyr", Type: OutlineTypeSubscription, XmlUrl: "https://www.zephyrproject.org/feed/"},
+					},
+				},
+			},
+		},
+		{
+			tname:  "same feed renamed differently",
+			ours:   setText(Path{1, 0}, "lobste.rs"),
+			theirs: setText(Path{1, 0}, "Lobsters (computing)"),
+			want: []Outline{
+				newSyncDocument().Body.Outlines[0],
+				{
+					Text: "News",
+					Outlines: []Outline{
+						{Text: "lobste.rs", Type: OutlineTypeSubscription, XmlUrl: "https://lobste.rs/rss"},
+						{Text: "Hacker News", Type: OutlineTypeSubscription, XmlUrl: "https://news.ycombinator.com/rss"},
+					},
+				},
+				newSyncDocument().Body.Outlines[2],
+			},
+			wantConflicts: `attribute: outline 1.0 "lobste.rs": text: base "Lobsters", ours "lobste.rs", theirs "Lobsters (computing)"
+`,
+		},
+		{
+			tname: "same feed moved differently",
+			ours: func(d *Document) error {
+				return d.Move(Path{0, 1}, Path{1, 2})
+			},
+			theirs: func(d *Document) error {
+				return d.Move(Path{0, 1}, Path{2, 1})
+			},
+			want: []Outline{
+				{
+					Text: "Programming",
+					Outlines: []Outline{
+						{Text: "Elixir Lang", Type: OutlineTypeSubscription, XmlUrl: "https://feeds.feedburner.com/ElixirLang"},
+					},
+				},
+				{
+					Text: "News",
+					Outlines: []Outline{
+						{Text: "Lobsters", Type: OutlineTypeSubscription, XmlUrl: "https://lobste.rs/rss"},
+						{Text: "Hacker News", Type: OutlineTypeSubscription, XmlUrl: "https://news.ycombinator.com/rss"},
+						{Text: "Go", Type: OutlineTypeSubscription, XmlUrl: "https://go.dev/blog/feed.atom"},
+					},
+				},
+				newSyncDocument().Body.Outlines[2],
+			},
+			wantConflicts: `parent: outline 1.2 "Go": base "Programming", ours "News", theirs "Unsorted"
+`,
+		},
+		{
+			tname: "same feed added differently",
+			ours: func(d *Document) error {
+				return d.Insert(Path{0, 2}, rust)
+			},
+			theirs: func(d *Document) error {
+				theirsRust := rust
+				theirsRust.Text = "Rust Blog"
+
+				return d.Insert(Path{3}, theirsRust)
+			},
+			want: []Outline{
+				{
+					Text: "Programming",
+					Outlines: []Outline{
+						{Text: "Elixir Lang", Type: OutlineTypeSubscription, XmlUrl: "https://feeds.feedburner.com/ElixirLang"},
+						{Text: "Go", Type: OutlineTypeSubscription, XmlUrl: "https://go.dev/blog/feed.atom"},
+						rust,
+					},
+				},
+				newSyncDocument().Body.Outlines[1],
+				newSyncDocument().Body.Outlines[2],
+			},
+			wantConflicts: `attribute: outline 0.2 "Rust": text: base "", ours "Rust", theirs "Rust Blog"
+parent: outline 0.2 "Rust": base "", ours "Programming", theirs ""
+`,
+		},
+		{
+			tname: "feed removed by ours and changed by theirs",
+			ours: func(d *Document) error {
+				_, err := d.Remove(Path{2, 0})
+
+				return err
+			},
+			theirs: setText(Path{2, 0}, "Zephyr Project"),
+			want: []Outline{
+				newSyncDocument().Body.Outlines[0],
+				newSyncDocument().Body.Outlines[1],
+				{
+					Text: "Unsorted",
+					Outlines: []Outline{
+						{Text: "Zephyr Project", Type: OutlineTypeSubscription, XmlUrl: "https://www.zephyrproject.org/feed/"},
+					},
+				},
+			},
+			wantConflicts: `ours-removed: outline 2.0 "Zephyr Project"
+`,
+		},
+		{
+			tname: "folder removed by theirs and changed by ours",
+			ours: func(d *Document) error {
+				return d.Insert(Path{2, 1}, rust)
+			},
+			theirs: func(d *Document) error {
+				_, err := d.Remove(Path{2})
+
+				return err
+			},
+			want: []Outline{
+				newSyncDocument().Body.Outlines[0],
+				newSyncDocument().Body.Outlines[1],
+				{
+					Text: "Unsorted",
+					Outlines: []Outline{
+						rust,
+					},
+				},
+			},
+			wantConflicts: `theirs-removed: outline 2 "Unsorted"
+`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.tname, func(t *testing.T) {
+			base := newSyncDocument()
+			ours := newSyncDocument()
+			theirs := newSyncDocument()
+
+			if tc.ours != nil {
+				if err := tc.ours(ours); err != nil {
+					t.Fatalf("want no error, got %q", err)
+				}
+			}
+			if tc.theirs != nil {
+				if err := tc.theirs(theirs); err != nil {
+					t.Fatalf("want no error, got %q", err)
+				}
+			}
+
+			oursText := Diff(base, ours).String()
+
+			got, gotConflicts, err := ThreeWayMerge(base, ours, theirs)
+			if err != nil {
+				t.Fatalf("want no error, got %q", err)
+			}
+
+			want := newSyncDocument()
+			want.Body.Outlines = tc.want
+
+			AssertDocumentsEqual(t, *got, *want)
+
+			if gotConflicts.String() != tc.wantConflicts {
+				t.Errorf("want Conflicts:\n%s\ngot:\n%s", tc.wantConflicts, gotConflicts)
+			}
+
+			// Merging does not modify the input documents
+			if diff := Diff(base, ours).String(); diff != oursText {
+				t.Errorf("want our Document to be unchanged, got changes:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestThreeWayMergeHead(t *testing.T) {
+	themeName := xml.Name{Space: "http://example.org/ns", Local: "theme"}
+	layoutName := xml.Name{Space: "http://example.org/ns", Local: "layout"}
+	dateCreated := mustDecodeRFC1123Time("Thu, 07 Nov 2024 20:18:01 GMT")
+
+	base := newSyncDocument()
+	base.Head.SetElement(layoutName, "list")
+
+	ours := newSyncDocument()
+	ours.Head.Title = "My subscriptions"
+	ours.Head.OwnerEmail = "jane@example.org"
+	ours.Head.ExpansionState = []int{1}
+	ours.Head.SetElement(layoutName, "list")
+
+	theirs := newSyncDocument()
+	theirs.Head.Title = "Feeds"
+	theirs.Head.OwnerName = "Jane"
+	theirs.Head.DateCreated = dateCreated
+	theirs.Head.ExpansionState = []int{4}
+	theirs.Head.SetElement(themeName, "dark")
+
+	got, gotConflicts, err := ThreeWayMerge(base, ours, theirs)
+	if err != nil {
+		t.Fatalf("want no error, got %q", err)
+	}
+
+	if got.Head.Title != "My subscriptions" {
+		t.Errorf("want Head > Title %q, got %q", "My subscriptions", got.Head.Title)
+	}
+	if got.Head.OwnerName != "Jane" {
+		t.Errorf("want Head > OwnerName %q, got %q", "Jane", got.Head.OwnerName)
+	}
+	if got.Head.OwnerEmail != "jane@example.org" {
+		t.Errorf("want Head > OwnerEmail %q, got %q", "jane@example.org", got.Head.OwnerEmail)
+	}
+	if !got.Head.DateCreated.Equal(dateCreated) {
+		t.Errorf("want Head > DateCreated %q, got %q", dateCreated, got.Head.DateCreated)
+	}
+	if !slices.Equal(got.Head.ExpansionState, ours.Head.ExpansionState) {
+		t.Errorf("want Head > ExpansionState %v, got %v", ours.Head.ExpansionState, got.Head.ExpansionState)
+	}
+	if value, _ := got.Head.Element(themeName); value != "dark" {
+		t.Errorf("want Head > theme %q, got %q", "dark", value)
+	}
+	if _, ok := got.Head.Element(layoutName); ok {
+		t.Error("want Head > layout to be removed")
+	}
+
+	wantConflicts := `attribute: head: title: base "Subscriptions", ours "My subscriptions", theirs "Feeds"
+`
+
+	if gotConflicts.String() != wantConflicts {
+		t.Errorf("want Conflicts:\n%s\ngot:\n%s", wantConflicts, gotConflicts)
+	}
+}
@@ -4,6 +4,11 @@
 package opml
 
 import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -13,6 +18,138 @@ var (
 	timeFormatDateTimeMicro string = "2006-01-02 15:04:05.000000"
 )
 
+// ErrUnsupportedDate is returned when a date does not match any known layout.
+var ErrUnsupportedDate = errors.New("opml: unsupported date format")
+
+// dateLayouts is the catalog of layouts used to parse dates, as found in OPML
+// documents exported by outliners and feed readers.
+//
+// Dates that do not specify a time zone are assumed to be in GMT.
+//
+// When parsing, Go accepts fractional seconds immediately after the seconds
+// field, even if the layout does not specify them.
+var dateLayouts = []string{
+	// RFC 822, as required by the OPML specification, with 4-digit years (RFC 1123)
+	time.RFC1123,
+	time.RFC1123Z,
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04 MST",
+	"Mon, 2 Jan 2006 15:04 -0700",
+
+	// RFC 822, without the optional day of the week
+	"2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04 MST",
+	"2 Jan 2006 15:04 -0700",
+
+	// RFC 822, with 2-digit years
+	"Mon, 2 Jan 06 15:04:05 MST",
+	"Mon, 2 Jan 06 15:04:05 -0700",
+	"Mon, 2 Jan 06 15:04 MST",
+	"Mon, 2 Jan 06 15:04 -0700",
+	"2 Jan 06 15:04:05 MST",
+	"2 Jan 06 15:04:05 -0700",
+	"2 Jan 06 15:04 MST",
+	"2 Jan 06 15:04 -0700",
+
+	// RFC 850, with full day names
+	"Monday, 02-Jan-06 15:04:05 MST",
+
+	// ISO 8601 and RFC 3339
+	time.RFC3339,
+	"2006-01-02T15:04:05-0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05 MST",
+	timeFormatDateTimeMicro,
+	"2006-01-02 15:04",
+	time.DateOnly,
+
+	// C and Unix date(1)
+	time.ANSIC,
+	time.UnixDate,
+}
+
+// dateZoneOffsets maps the time zone abbreviations found in dates to their offset.
+//
+// When parsing an unknown abbreviation, Go fabricates a location with a zero
+// offset; dates using the abbreviations listed here are adjusted accordingly.
+var dateZoneOffsets = map[string]time.Duration{
+	// RFC 822
+	"UT":  0,
+	"UTC": 0,
+	"GMT": 0,
+	"EST": -5 * time.Hour,
+	"EDT": -4 * time.Hour,
+	"CST": -6 * time.Hour,
+	"CDT": -5 * time.Hour,
+	"MST": -7 * time.Hour,
+	"MDT": -6 * time.Hour,
+	"PST": -8 * time.Hour,
+	"PDT": -7 * time.Hour,
+
+	// Other common abbreviations
+	"AKST": -9 * time.Hour,
+	"AKDT": -8 * time.Hour,
+	"HST":  -10 * time.Hour,
+	"WET":  0,
+	"WEST": 1 * time.Hour,
+	"BST":  1 * time.Hour,
+	"CET":  1 * time.Hour,
+	"CEST": 2 * time.Hour,
+	"EET":  2 * time.Hour,
+	"EEST": 3 * time.Hour,
+	"MSK":  3 * time.Hour,
+	"JST":  9 * time.Hour,
+	"KST":  9 * time.Hour,
+	"AEST": 10 * time.Hour,
+	"AEDT": 11 * time.Hour,
+	"NZST": 12 * time.Hour,
+	"NZDT": 13 * time.Hour,
+}
+
+// A DateParser parses the textual representation of a date.
+type DateParser func(value string) (time.Time, error)
+
+var (
+	dateRegistryMutex     sync.RWMutex
+	registeredDateLayouts []string
+	registeredDateParsers []DateParser
+)
+
+// RegisterDateLayout registers layouts used to parse dates, in addition to the
+// built-in catalog.
+//
+// Registered layouts are tried in registration order, after the built-in ones.
+func RegisterDateLayout(layouts ...string) {
+	dateRegistryMutex.Lock()
+	defer dateRegistryMutex.Unlock()
+
+	registeredDateLayouts = append(registeredDateLayouts, layouts...)
+}
+
+// RegisterDateParser registers a parser used to parse dates that do not match
+// any layout.
+//
+// Registered parsers are tried in registration order, after all layouts.
+func RegisterDateParser(parser DateParser) {
+	dateRegistryMutex.Lock()
+	defer dateRegistryMutex.Unlock()
+
+	registeredDateParsers = append(registeredDateParsers, parser)
+}
+
+// ParseDate parses a date using the built-in catalog of layouts, then the
+// registered layouts and parsers.
+//
+// It returns an error wrapping ErrUnsupportedDate if the date cannot be parsed.
+func ParseDate(value string) (time.Time, error) {
+	return decodeTime(value)
+}
+
 func gmtLocation() *time.Location {
 	location, err := time.LoadLocation("GMT")
 	if err != nil {
@@ -26,16 +163,102 @@ func encodeRFC1123Time(t time.Time) string {
 	return t.In(locationGMT).Format(time.RFC1123)
 }
 
+// A dateSource records the textual representation a date was decoded from, so
+// it can be written back unchanged.
+type dateSource struct {
+	text    string
+	decoded time.Time
+}
+
+func newDateSource(text string, decoded time.Time) dateSource {
+	return dateSource{
+		text:    text,
+		decoded: decoded,
+	}
+}
+
+// encode returns the recorded text if t is the date that was decoded, in the same
+// time zone, or its RFC 1123 representation otherwise.
+func (s dateSource) encode(t time.Time) string {
+	if s.text == "" || !t.Equal(s.decoded) {
+		return encodeRFC1123Time(t)
+	}
+
+	name, offset := t.Zone()
+	decodedName, decodedOffset := s.decoded.Zone()
+
+	if name != decodedName || offset != decodedOffset {
+		return encodeRFC1123Time(t)
+	}
+
+	return s.text
+}
+
 func decodeTime(timeStr string) (time.Time, error) {
-	parsed, err := time.ParseInLocation(time.RFC1123, timeStr, locationGMT)
-	if err == nil {
-		return parsed, nil
+	value := normalizeDate(timeStr)
+
+	for _, layout := range dateLayouts {
+		if parsed, ok := parseDateLayout(layout, value); ok {
+			return parsed, nil
+		}
 	}
 
-	parsed, err = time.ParseInLocation(timeFormatDateTimeMicro, timeStr, locationGMT)
-	if err == nil {
-		return parsed, nil
+	// Registered parsers may register other layouts and parsers, so they are
+	// run without holding the lock
+	dateRegistryMutex.RLock()
+	layouts := slices.Clone(registeredDateLayouts)
+	parsers := slices.Clone(registeredDateParsers)
+	dateRegistryMutex.RUnlock()
+
+	for _, layout := range layouts {
+		if parsed, ok := parseDateLayout(layout, value); ok {
+			return parsed, nil
+		}
 	}
 
-	return time.Time{}, err
+	for _, parser := range parsers {
+		if parsed, err := parser(timeStr); err == nil {
+			return parsed, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%w: %q", ErrUnsupportedDate, timeStr)
+}
+
+// normalizeDate trims and collapses whitespace, and replaces the "UT" time zone
+// that is defined by RFC 822 but not supported by the time package.
+func normalizeDate(value string) string {
+	value = strings.Join(strings.Fields(value), " ")
+
+	if strings.HasSuffix(value, " UT") {
+		value += "C"
+	}
+
+	return value
+}
+
+func parseDateLayout(layout string, value string) (time.Time, bool) {
+	parsed, err := time.ParseInLocation(layout, value, locationGMT)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	zoneName, zoneOffset := parsed.Zone()
+	if zoneOffset != 0 {
+		return parsed, true
+	}
+
+	offset, ok := dateZoneOffsets[zoneName]
+	if !ok || offset == 0 {
+		return parsed, true
+	}
+
+	// Apply the offset of the abbreviated time zone to the wall clock
+	adjusted := time.Date(
+		parsed.Year(), parsed.Month(), parsed.Day(),
+		parsed.Hour(), parsed.Minute(), parsed.Second(), parsed.Nanosecond(),
+		time.FixedZone(zoneName, int(offset.Seconds())),
+	)
+
+	return adjusted, true
 }
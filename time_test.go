@@ -4,6 +4,9 @@
 package opml
 
 import (
+	"errors"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -19,11 +22,116 @@ func TestParseTime(t *testing.T) {
 			dateStr: "Mon, 27 Feb 2006 12:09:48 GMT",
 			want:    mustDecodeRFC1123Time("Mon, 27 Feb 2006 12:09:48 GMT"),
 		},
+		{
+			tname:   "RFC 1123 with fractional seconds",
+			dateStr: "Thu, 07 Nov 2024 20:18:01.109756 GMT",
+			want:    time.Date(2024, time.November, 7, 20, 18, 1, 109756000, time.UTC),
+		},
+		{
+			tname:   "RFC 1123 with numeric zone",
+			dateStr: "Mon, 27 Feb 2006 12:09:48 +0200",
+			want:    time.Date(2006, time.February, 27, 10, 9, 48, 0, time.UTC),
+		},
+		{
+			tname:   "RFC 1123 with single-digit day",
+			dateStr: "Wed, 1 Jun 2005 18:45:00 GMT",
+			want:    time.Date(2005, time.June, 1, 18, 45, 0, 0, time.UTC),
+		},
+		{
+			tname:   "RFC 1123 without seconds",
+			dateStr: "Mon, 27 Feb 2006 12:09 GMT",
+			want:    time.Date(2006, time.February, 27, 12, 9, 0, 0, time.UTC),
+		},
+		{
+			tname:   "RFC 1123 with UT zone",
+			dateStr: "Mon, 27 Feb 2006 12:09:48 UT",
+			want:    time.Date(2006, time.February, 27, 12, 9, 48, 0, time.UTC),
+		},
+		{
+			tname:   "RFC 1123 with UTC zone",
+			dateStr: "Mon, 27 Feb 2006 12:09:48 UTC",
+			want:    time.Date(2006, time.February, 27, 12, 9, 48, 0, time.UTC),
+		},
+		{
+			tname:   "RFC 1123 with EST zone",
+			dateStr: "Mon, 27 Feb 2006 12:09:48 EST",
+			want:    time.Date(2006, time.February, 27, 17, 9, 48, 0, time.UTC),
+		},
+		{
+			tname:   "RFC 1123 with PDT zone",
+			dateStr: "Tue, 15 Aug 2006 08:00:00 PDT",
+			want:    time.Date(2006, time.August, 15, 15, 0, 0, 0, time.UTC),
+		},
+		{
+			tname:   "RFC 1123 with CEST zone",
+			dateStr: "Tue, 15 Aug 2006 08:00:00 CEST",
+			want:    time.Date(2006, time.August, 15, 6, 0, 0, 0, time.UTC),
+		},
+		{
+			tname:   "RFC 1123 with lowercase month and extra whitespace",
+			dateStr: "  Mon,  27 feb 2006   12:09:48 GMT ",
+			want:    time.Date(2006, time.February, 27, 12, 9, 48, 0, time.UTC),
+		},
+		{
+			tname:   "RFC 822 without weekday",
+			dateStr: "27 Feb 2006 12:09:48 GMT",
+			want:    time.Date(2006, time.February, 27, 12, 9, 48, 0, time.UTC),
+		},
+		{
+			tname:   "RFC 822 without weekday, with numeric zone",
+			dateStr: "27 Feb 2006 12:09:48 -0500",
+			want:    time.Date(2006, time.February, 27, 17, 9, 48, 0, time.UTC),
+		},
+		{
+			tname:   "RFC 822 with 2-digit year",
+			dateStr: "Mon, 27 Feb 06 12:09:48 GMT",
+			want:    time.Date(2006, time.February, 27, 12, 9, 48, 0, time.UTC),
+		},
+		{
+			tname:   "RFC 822 with 2-digit year, without weekday nor seconds",
+			dateStr: "27 Feb 06 12:09 MST",
+			want:    time.Date(2006, time.February, 27, 19, 9, 0, 0, time.UTC),
+		},
+		{
+			tname:   "RFC 822 with 2-digit year in the previous century",
+			dateStr: "Fri, 31 Dec 99 23:59:59 +0000",
+			want:    time.Date(1999, time.December, 31, 23, 59, 59, 0, time.UTC),
+		},
+		{
+			tname:   "RFC 850",
+			dateStr: "Monday, 27-Feb-06 12:09:48 GMT",
+			want:    time.Date(2006, time.February, 27, 12, 9, 48, 0, time.UTC),
+		},
+		{
+			tname:   "RFC 3339",
+			dateStr: "2006-02-27T12:09:48Z",
+			want:    time.Date(2006, time.February, 27, 12, 9, 48, 0, time.UTC),
+		},
+		{
+			tname:   "RFC 3339 with offset and fractional seconds",
+			dateStr: "2006-02-27T12:09:48.123+01:00",
+			want:    time.Date(2006, time.February, 27, 11, 9, 48, 123000000, time.UTC),
+		},
+		{
+			tname:   "ISO 8601 without zone",
+			dateStr: "2006-02-27T12:09:48",
+			want:    time.Date(2006, time.February, 27, 12, 9, 48, 0, time.UTC),
+		},
 		{
 			tname:   "Newsblur",
 			dateStr: "2024-11-07 20:18:01.109756",
 			want:    mustDecodeRFC1123Time("Thu, 07 Nov 2024 20:18:01.109756 GMT"),
 		},
+		{
+			tname:   "date only",
+			dateStr: "2006-02-27",
+			want:    time.Date(2006, time.February, 27, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			tname:   "Unix date",
+			dateStr: "Mon Feb 27 12:09:48 PST 2006",
+			want:    time.Date(2006, time.February, 27, 20, 9, 48, 0, time.UTC),
+		},
 	}
 
 	for _, tc := range cases {
@@ -40,6 +148,143 @@ func TestParseTime(t *testing.T) {
 	}
 }
 
+func TestParseTimeError(t *testing.T) {
+	cases := []struct {
+		tname   string
+		dateStr string
+	}{
+		{
+			tname:   "empty",
+			dateStr: "",
+		},
+		{
+			tname:   "garbage",
+			dateStr: "yesterday",
+		},
+		{
+			tname:   "invalid day",
+			dateStr: "Mon, 32 Feb 2006 12:09:48 GMT",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.tname, func(t *testing.T) {
+			_, err := decodeTime(tc.dateStr)
+			if !errors.Is(err, ErrUnsupportedDate) {
+				t.Errorf("want error %q, got %q", ErrUnsupportedDate, err)
+			}
+		})
+	}
+}
+
+func TestParseTimeZoneOffset(t *testing.T) {
+	got, err := decodeTime("Mon, 27 Feb 2006 12:09:48 EST")
+	if err != nil {
+		t.Fatalf("want no error, got %q", err)
+	}
+
+	name, offset := got.Zone()
+	if name != "EST" {
+		t.Errorf("want zone name %q, got %q", "EST", name)
+	}
+	if offset != -5*60*60 {
+		t.Errorf("want zone offset %d, got %d", -5*60*60, offset)
+	}
+}
+
+func TestRegisterDate(t *testing.T) {
+	savedLayouts := registeredDateLayouts
+	savedParsers := registeredDateParsers
+	t.Cleanup(func() {
+		registeredDateLayouts = savedLayouts
+		registeredDateParsers = savedParsers
+	})
+
+	layoutDateStr := "27/02/2006 12h09"
+	parserDateStr := "@1141042188"
+
+	if _, err := ParseDate(layoutDateStr); !errors.Is(err, ErrUnsupportedDate) {
+		t.Fatalf("want error %q, got %q", ErrUnsupportedDate, err)
+	}
+	if _, err := ParseDate(parserDateStr); !errors.Is(err, ErrUnsupportedDate) {
+		t.Fatalf("want error %q, got %q", ErrUnsupportedDate, err)
+	}
+
+	RegisterDateLayout("02/01/2006 15h04")
+	RegisterDateParser(func(value string) (time.Time, error) {
+		seconds, ok := strings.CutPrefix(value, "@")
+		if !ok {
+			return time.Time{}, errors.New("not a timestamp")
+		}
+
+		parsed, err := time.Parse("2006-01-02", "1970-01-01")
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		duration, err := time.ParseDuration(seconds + "s")
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		return parsed.Add(duration), nil
+	})
+
+	got, err := ParseDate(layoutDateStr)
+	if err != nil {
+		t.Fatalf("want no error, got %q", err)
+	}
+	if want := time.Date(2006, time.February, 27, 12, 9, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("want %q, got %q", want, got)
+	}
+
+	got, err = ParseDate(parserDateStr)
+	if err != nil {
+		t.Fatalf("want no error, got %q", err)
+	}
+	if want := time.Date(2006, time.February, 27, 12, 9, 48, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestRegisterDateFromParser(t *testing.T) {
+	savedLayouts := registeredDateLayouts
+	savedParsers := registeredDateParsers
+	t.Cleanup(func() {
+		registeredDateLayouts = savedLayouts
+		registeredDateParsers = savedParsers
+	})
+
+	// A parser registering a layout the first time it is called
+	var once sync.Once
+	RegisterDateParser(func(value string) (time.Time, error) {
+		once.Do(func() {
+			RegisterDateLayout("2006/01/02")
+		})
+
+		return time.Time{}, errors.New("not supported")
+	})
+
+	done := make(chan error)
+	go func() {
+		_, err := ParseDate("2006/02/27")
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrUnsupportedDate) {
+			t.Fatalf("want error %q, got %q", ErrUnsupportedDate, err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("want ParseDate to return, got deadlock")
+	}
+
+	if _, err := ParseDate("2006/02/27"); err != nil {
+		t.Errorf("want no error, got %q", err)
+	}
+}
+
 func mustDecodeRFC1123Time(dateStr string) time.Time {
 	parsed, err := time.ParseInLocation(time.RFC1123, dateStr, locationGMT)
 	if err != nil {
@@ -48,3 +293,68 @@ func mustDecodeRFC1123Time(dateStr string) time.Time {
 
 	return parsed
 }
+
+func TestRoundtripDates(t *testing.T) {
+	input := `<?xml version="1.0" encoding="UTF-8"?>
+<opml version="2.0">
+  <head>
+    <dateCreated>Thu, 07 Nov 2024 20:18:01.109756 GMT</dateCreated>
+    <dateModified>Mon, 27 Feb 2006 12:09:48 +0200</dateModified>
+  </head>
+  <body>
+    <outline text="Dated" created="2024-11-07 20:18:01.109756"></outline>
+  </body>
+</opml>`
+
+	cases := []struct {
+		tname  string
+		modify func(d *Document)
+		want   string
+	}{
+		{
+			tname:  "unchanged",
+			modify: func(d *Document) {},
+			want:   input,
+		},
+		{
+			tname: "modified",
+			modify: func(d *Document) {
+				d.Head.DateModified = d.Head.DateModified.Add(time.Hour)
+				d.Body.Outlines[0].Created = d.Body.Outlines[0].Created.Truncate(time.Second)
+			},
+			want: strings.NewReplacer(
+				"Mon, 27 Feb 2006 12:09:48 +0200", "Mon, 27 Feb 2006 11:09:48 GMT",
+				"2024-11-07 20:18:01.109756", "Thu, 07 Nov 2024 20:18:01 GMT",
+			).Replace(input),
+		},
+		{
+			tname: "same instant in another zone",
+			modify: func(d *Document) {
+				d.Head.DateModified = d.Head.DateModified.In(time.UTC)
+			},
+			want: strings.Replace(input, "Mon, 27 Feb 2006 12:09:48 +0200", "Mon, 27 Feb 2006 10:09:48 GMT", 1),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.tname, func(t *testing.T) {
+			document, err := UnmarshalString(input)
+			if err != nil {
+				t.Fatalf("want no error, got %q", err)
+			}
+
+			tc.modify(document)
+
+			gotBytes, err := Marshal(document)
+			if err != nil {
+				t.Fatalf("want no error, got %q", err)
+			}
+
+			got := string(gotBytes)
+
+			if got != tc.want {
+				t.Errorf("\nwant:\n%s\n\ngot:\n%s", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright (c) VirtualTam
+// SPDX-License-Identifier: MIT
+
+package opml
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// feedburnerHosts lists the hosts serving FeedBurner feeds, that are
+// normalized to the canonical FeedBurner host.
+var feedburnerHosts = []string{
+	"feeds.feedburner.com",
+	"feeds2.feedburner.com",
+	"feedproxy.google.com",
+	"feeds.feedburner.google.com",
+}
+
+// trackingParameters lists the query parameters used for tracking, that are
+// removed by NormalizeURL.
+var trackingParameters = []string{
+	"fbclid",
+	"gclid",
+	"mc_cid",
+	"mc_eid",
+	"msclkid",
+	"yclid",
+}
+
+// trackingParameterPrefixes lists the prefixes of query parameters used for
+// tracking, that are removed by NormalizeURL.
+var trackingParameterPrefixes = []string{
+	"utm_",
+}
+
+// NormalizeURL returns the normalized form of a feed or website URL, so that
+// URLs pointing to the same resource can be compared.
+//
+// The normalized form:
+//   - uses the https scheme for http and https URLs;
+//   - has a lowercase host, without the default port;
+//   - has no trailing slash, fragment, nor tracking query parameters;
+//   - has sorted query parameters;
+//   - uses the canonical FeedBurner host, without the format query parameter.
+//
+// URLs that cannot be parsed are returned with surrounding whitespace removed.
+func NormalizeURL(rawURL string) string {
+	rawURL = strings.TrimSpace(rawURL)
+
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	if u.Scheme == "http" {
+		u.Scheme = "https"
+	}
+
+	host := strings.ToLower(u.Hostname())
+	port := u.Port()
+
+	if port == "80" || port == "443" {
+		port = ""
+	}
+
+	isFeedburner := slices.Contains(feedburnerHosts, host)
+	if isFeedburner {
+		host = feedburnerHosts[0]
+	}
+
+	if strings.Contains(host, ":") {
+		// IPv6 address
+		host = "[" + host + "]"
+	}
+
+	u.Host = host
+	if port != "" {
+		u.Host += ":" + port
+	}
+
+	u.Fragment = ""
+	u.RawFragment = ""
+
+	// Trim the escaped path, so that escaped slashes are not decoded
+	escapedPath := strings.TrimRight(u.EscapedPath(), "/")
+	if path, err := url.PathUnescape(escapedPath); err == nil {
+		u.Path = path
+		u.RawPath = escapedPath
+	}
+
+	query := u.Query()
+
+	for parameter := range query {
+		if isTrackingParameter(parameter) || (isFeedburner && parameter == "format") {
+			query.Del(parameter)
+		}
+	}
+
+	// Encode sorts the query parameters by key
+	u.RawQuery = query.Encode()
+	u.ForceQuery = false
+
+	return u.String()
+}
+
+func isTrackingParameter(parameter string) bool {
+	parameter = strings.ToLower(parameter)
+
+	if slices.Contains(trackingParameters, parameter) {
+		return true
+	}
+
+	for _, prefix := range trackingParameterPrefixes {
+		if strings.HasPrefix(parameter, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
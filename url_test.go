@@ -0,0 +1,120 @@
+// Copyright (c) VirtualTam
+// SPDX-License-Identifier: MIT
+
+package opml
+
+import "testing"
+
+func TestNormalizeURL(t *testing.T) {
+	cases := []struct {
+		tname string
+		input string
+		want  string
+	}{
+		{
+			tname: "already normalized",
+			input: "https://example.org/feed.xml",
+			want:  "https://example.org/feed.xml",
+		},
+		{
+			tname: "http scheme",
+			input: "http://example.org/feed.xml",
+			want:  "https://example.org/feed.xml",
+		},
+		{
+			tname: "uppercase scheme and host",
+			input: "HTTPS://Example.ORG/Feed.xml",
+			want:  "https://example.org/Feed.xml",
+		},
+		{
+			tname: "default port",
+			input: "http://example.org:80/feed.xml",
+			want:  "https://example.org/feed.xml",
+		},
+		{
+			tname: "custom port",
+			input: "https://example.org:8443/feed.xml",
+			want:  "https://example.org:8443/feed.xml",
+		},
+		{
+			tname: "IPv6 host",
+			input: "http://[::1]:443/feed.xml",
+			want:  "https://[::1]/feed.xml",
+		},
+		{
+			tname: "trailing slash",
+			input: "https://www.zephyrproject.org/feed/",
+			want:  "https://www.zephyrproject.org/feed",
+		},
+		{
+			tname: "escaped slash",
+			input: "https://example.org/tags/c%2Fc%2B%2B/feed/",
+			want:  "https://example.org/tags/c%2Fc%2B%2B/feed",
+		},
+		{
+			tname: "root path",
+			input: "https://example.org/",
+			want:  "https://example.org",
+		},
+		{
+			tname: "fragment",
+			input: "https://example.org/feed.xml#latest",
+			want:  "https://example.org/feed.xml",
+		},
+		{
+			tname: "tracking parameters",
+			input: "https://example.org/feed.xml?utm_source=feedly&page=2&fbclid=abc&UTM_Medium=rss",
+			want:  "https://example.org/feed.xml?page=2",
+		},
+		{
+			tname: "sorted query parameters",
+			input: "https://example.org/feed?type=rss&lang=en",
+			want:  "https://example.org/feed?lang=en&type=rss",
+		},
+		{
+			tname: "empty query",
+			input: "https://example.org/feed?",
+			want:  "https://example.org/feed",
+		},
+		{
+			tname: "feedburner",
+			input: "http://feeds2.feedburner.com/ElixirLang?format=xml",
+			want:  "https://feeds.feedburner.com/ElixirLang",
+		},
+		{
+			tname: "feedproxy",
+			input: "http://feedproxy.google.com/PythonInsider/",
+			want:  "https://feeds.feedburner.com/PythonInsider",
+		},
+		{
+			tname: "format parameter on another host",
+			input: "https://example.org/feed?format=xml",
+			want:  "https://example.org/feed?format=xml",
+		},
+		{
+			tname: "surrounding whitespace",
+			input: "  https://example.org/feed.xml\n",
+			want:  "https://example.org/feed.xml",
+		},
+		{
+			tname: "not a URL",
+			input: " tag:blog.golang.org,2013:blog.golang.org ",
+			want:  "tag:blog.golang.org,2013:blog.golang.org",
+		},
+		{
+			tname: "empty",
+			input: "",
+			want:  "",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.tname, func(t *testing.T) {
+			got := NormalizeURL(tc.input)
+
+			if got != tc.want {
+				t.Errorf("want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
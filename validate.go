@@ -0,0 +1,145 @@
+// Copyright (c) VirtualTam
+// SPDX-License-Identifier: MIT
+
+package opml
+
+import (
+	"fmt"
+	"slices"
+)
+
+// A Severity indicates how a Diagnostic impacts the validity of a Document.
+type Severity string
+
+const (
+	// SeverityError indicates the Document does not conform to the OPML specification.
+	SeverityError Severity = "error"
+
+	// SeverityWarning indicates the Document conforms to the OPML specification,
+	// but may not be processed as intended.
+	SeverityWarning Severity = "warning"
+)
+
+// Identifiers of the rules checked by Validate.
+const (
+	// The OPML version must be one of 1.0, 1.1 or 2.0.
+	RuleVersion string = "version"
+
+	// Line numbers listed in the expansion state must refer to existing Outlines,
+	// and should be listed in ascending order.
+	RuleExpansionState string = "expansion-state"
+
+	// The vertical scroll state must refer to an existing Outline.
+	RuleVertScrollState string = "vert-scroll-state"
+
+	// Every Outline must have a text attribute.
+	RuleOutlineText string = "outline-text"
+
+	// Subscription Outlines must have an xmlUrl attribute.
+	RuleSubscriptionXmlUrl string = "subscription-xml-url"
+
+	// Inclusion Outlines must have a url attribute.
+	RuleInclusionUrl string = "inclusion-url"
+
+	// Link Outlines must have a url attribute.
+	RuleLinkUrl string = "link-url"
+)
+
+// A Diagnostic reports a Document that does not satisfy a validation rule.
+type Diagnostic struct {
+	// The identifier of the rule that is not satisfied.
+	Rule string `json:"rule"`
+
+	// The Severity of the issue.
+	Severity Severity `json:"severity"`
+
+	// The Path of the offending Outline, if the issue concerns an Outline.
+	Path Path `json:"path,omitempty"`
+
+	// A human-readable description of the issue.
+	Message string `json:"message"`
+}
+
+func (d Diagnostic) String() string {
+	if d.Path == nil {
+		return fmt.Sprintf("%s: %s: %s", d.Severity, d.Rule, d.Message)
+	}
+
+	return fmt.Sprintf("%s: %s: outline %s: %s", d.Severity, d.Rule, d.Path, d.Message)
+}
+
+// Diagnostics is a list of Diagnostic.
+type Diagnostics []Diagnostic
+
+// HasErrors returns whether the list contains a Diagnostic with SeverityError.
+func (ds Diagnostics) HasErrors() bool {
+	return slices.ContainsFunc(ds, func(d Diagnostic) bool {
+		return d.Severity == SeverityError
+	})
+}
+
+// Validate checks whether a Document conforms to the OPML specification, and
+// returns a Diagnostic for each issue found.
+//
+// See https://opml.org/spec2.opml for details on the OPML specification.
+func Validate(d *Document) Diagnostics {
+	var diagnostics Diagnostics
+
+	report := func(rule string, severity Severity, path Path, format string, a ...any) {
+		diagnostics = append(diagnostics, Diagnostic{
+			Rule:     rule,
+			Severity: severity,
+			Path:     path,
+			Message:  fmt.Sprintf(format, a...),
+		})
+	}
+
+	switch d.Version {
+	case Version1, Version1_1, Version2:
+	case "":
+		report(RuleVersion, SeverityError, nil, "missing version")
+	default:
+		report(RuleVersion, SeverityError, nil, "invalid version %q", d.Version)
+	}
+
+	nLines := 0
+
+	for path, outline := range d.All() {
+		nLines++
+
+		if outline.Text == "" {
+			report(RuleOutlineText, SeverityError, path, "missing text attribute")
+		}
+
+		switch outline.Type {
+		case OutlineTypeSubscription:
+			if outline.XmlUrl == "" {
+				report(RuleSubscriptionXmlUrl, SeverityError, path, "missing xmlUrl attribute")
+			}
+		case OutlineTypeInclusion:
+			if outline.Url == "" {
+				report(RuleInclusionUrl, SeverityError, path, "missing url attribute")
+			}
+		case OutlineTypeLink:
+			if outline.Url == "" {
+				report(RuleLinkUrl, SeverityError, path, "missing url attribute")
+			}
+		}
+	}
+
+	for i, line := range d.Head.ExpansionState {
+		if line < 1 || line > nLines {
+			report(RuleExpansionState, SeverityError, nil, "line %d is out of range [1, %d]", line, nLines)
+		}
+
+		if i > 0 && line <= d.Head.ExpansionState[i-1] {
+			report(RuleExpansionState, SeverityWarning, nil, "line %d is not listed in ascending order", line)
+		}
+	}
+
+	if d.Head.VertScrollState < 0 || d.Head.VertScrollState > nLines {
+		report(RuleVertScrollState, SeverityWarning, nil, "line %d is out of range [1, %d]", d.Head.VertScrollState, nLines)
+	}
+
+	return diagnostics
+}
@@ -0,0 +1,170 @@
+// Copyright (c) VirtualTam
+// SPDX-License-Identifier: MIT
+
+package opml
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestValidateSpec(t *testing.T) {
+	inputFilePaths, err := filepath.Glob(filepath.Join("testdata", "spec", "unmarshal", "*.opml"))
+	if err != nil {
+		t.Fatalf("failed to list input files: %q", err)
+	}
+
+	if len(inputFilePaths) == 0 {
+		t.Fatal("want input files, got none")
+	}
+
+	for _, inputFilePath := range inputFilePaths {
+		t.Run(filepath.Base(inputFilePath), func(t *testing.T) {
+			document, err := UnmarshalFile(inputFilePath)
+			if err != nil {
+				t.Fatalf("want no error, got %q", err)
+			}
+
+			for _, diagnostic := range Validate(document) {
+				t.Errorf("want no diagnostic, got %q", diagnostic)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		tname    string
+		document Document
+		want     Diagnostics
+	}{
+		{
+			tname: "valid",
+			document: Document{
+				Version: Version2,
+				Head: Head{
+					ExpansionState:  []int{1},
+					VertScrollState: 1,
+				},
+				Body: Body{
+					Outlines: []Outline{
+						{
+							Text: "Linux",
+							Outlines: []Outline{
+								{Text: "Bits from Debian", Type: OutlineTypeSubscription, XmlUrl: "https://bits.debian.org/feeds/atom.xml"},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			tname:    "missing version",
+			document: Document{},
+			want: Diagnostics{
+				{Rule: RuleVersion, Severity: SeverityError},
+			},
+		},
+		{
+			tname:    "invalid version",
+			document: Document{Version: "2"},
+			want: Diagnostics{
+				{Rule: RuleVersion, Severity: SeverityError},
+			},
+		},
+		{
+			tname: "invalid outlines",
+			document: Document{
+				Version: Version2,
+				Body: Body{
+					Outlines: []Outline{
+						{
+							Text: "Directory",
+							Outlines: []Outline{
+								{Type: OutlineTypeText},
+								{Text: "Feed", Type: OutlineTypeSubscription, HtmlUrl: "https://example.org"},
+							},
+						},
+						{Text: "Inclusion", Type: OutlineTypeInclusion},
+						{Text: "Link", Type: OutlineTypeLink},
+					},
+				},
+			},
+			want: Diagnostics{
+				{Rule: RuleOutlineText, Severity: SeverityError, Path: Path{0, 0}},
+				{Rule: RuleSubscriptionXmlUrl, Severity: SeverityError, Path: Path{0, 1}},
+				{Rule: RuleInclusionUrl, Severity: SeverityError, Path: Path{1}},
+				{Rule: RuleLinkUrl, Severity: SeverityError, Path: Path{2}},
+			},
+		},
+		{
+			tname: "invalid expansion state",
+			document: Document{
+				Version: Version2,
+				Head: Head{
+					ExpansionState:  []int{2, 1, 3},
+					VertScrollState: 4,
+				},
+				Body: Body{
+					Outlines: []Outline{
+						{Text: "Directory", Outlines: []Outline{{Text: "Child"}}},
+					},
+				},
+			},
+			want: Diagnostics{
+				{Rule: RuleExpansionState, Severity: SeverityWarning},
+				{Rule: RuleExpansionState, Severity: SeverityError},
+				{Rule: RuleVertScrollState, Severity: SeverityWarning},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.tname, func(t *testing.T) {
+			got := Validate(&tc.document)
+
+			if len(got) != len(tc.want) {
+				t.Fatalf("want %d Diagnostics, got %d: %v", len(tc.want), len(got), got)
+			}
+
+			for index, want := range tc.want {
+				if got[index].Rule != want.Rule {
+					t.Errorf("want Diagnostic %d Rule %q, got %q", index, want.Rule, got[index].Rule)
+				}
+				if got[index].Severity != want.Severity {
+					t.Errorf("want Diagnostic %d Severity %q, got %q", index, want.Severity, got[index].Severity)
+				}
+				if !slices.Equal(got[index].Path, want.Path) {
+					t.Errorf("want Diagnostic %d Path %q, got %q", index, want.Path, got[index].Path)
+				}
+			}
+
+			wantErrors := slices.ContainsFunc(tc.want, func(d Diagnostic) bool { return d.Severity == SeverityError })
+			if got.HasErrors() != wantErrors {
+				t.Errorf("want HasErrors %t, got %t", wantErrors, got.HasErrors())
+			}
+		})
+	}
+}
+
+func TestValidateFeedReader(t *testing.T) {
+	for _, inputFileName := range []string{"feedly.opml", "newsblur.opml"} {
+		t.Run(inputFileName, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", "feedreader", inputFileName))
+			if err != nil {
+				t.Fatalf("failed to read input file: %q", err)
+			}
+
+			document, err := Unmarshal(data)
+			if err != nil {
+				t.Fatalf("want no error, got %q", err)
+			}
+
+			if diagnostics := Validate(document); diagnostics.HasErrors() {
+				t.Errorf("want no errors, got %v", diagnostics)
+			}
+		})
+	}
+}
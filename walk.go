@@ -0,0 +1,234 @@
+// Copyright (c) VirtualTam
+// SPDX-License-Identifier: MIT
+
+package opml
+
+import (
+	"errors"
+	"iter"
+)
+
+// A TraversalOrder defines the order in which Outlines are visited.
+type TraversalOrder int
+
+const (
+	// DepthFirst visits an Outline, then its subordinated Outlines, before its
+	// next sibling; this is the document order.
+	DepthFirst TraversalOrder = iota
+
+	// BreadthFirst visits all Outlines of a given depth before the Outlines
+	// of the next depth.
+	BreadthFirst
+)
+
+// SkipOutlines and SkipAll are not named with an Err prefix, as they do not
+// report failures; they follow the precedent of fs.SkipDir and fs.SkipAll.
+var (
+	// SkipOutlines is used as a return value from a WalkFunc to indicate that
+	// the subordinated Outlines of the visited Outline are to be skipped.
+	SkipOutlines = errors.New("skip subordinated outlines")
+
+	// SkipAll is used as a return value from a WalkFunc to indicate that all
+	// remaining Outlines are to be skipped.
+	SkipAll = errors.New("skip all outlines")
+)
+
+// A WalkFunc is called by Walk for each visited Outline, along with its Path.
+//
+// If the function returns SkipOutlines, the subordinated Outlines of the
+// visited Outline are skipped. If the function returns SkipAll, all remaining
+// Outlines are skipped. Any other error stops the traversal, and is returned by Walk.
+type WalkFunc func(path Path, outline *Outline) error
+
+// Walk visits the Outlines of the Document in the given order, calling fn for
+// each Outline.
+func (d *Document) Walk(order TraversalOrder, fn WalkFunc) error {
+	return walkOutlines(d.Body.Outlines, order, fn)
+}
+
+// All returns an iterator over the Outlines of the Document and their Path,
+// in document order.
+func (d *Document) All() iter.Seq2[Path, *Outline] {
+	return d.Traverse(DepthFirst).All()
+}
+
+// AllBreadthFirst returns an iterator over the Outlines of the Document and
+// their Path, in breadth-first order.
+func (d *Document) AllBreadthFirst() iter.Seq2[Path, *Outline] {
+	return d.Traverse(BreadthFirst).All()
+}
+
+// Traverse returns a Traversal of the Outlines of the Document in the given order.
+func (d *Document) Traverse(order TraversalOrder) *Traversal {
+	return &Traversal{
+		walk:  d.Walk,
+		order: order,
+	}
+}
+
+// Walk visits the subordinated Outlines of the Outline in the given order,
+// calling fn for each Outline.
+//
+// Paths are relative to the Outline.
+func (o *Outline) Walk(order TraversalOrder, fn WalkFunc) error {
+	return walkOutlines(o.Outlines, order, fn)
+}
+
+// Descendants returns an iterator over the subordinated Outlines of the Outline
+// and their Path relative to the Outline, in document order.
+func (o *Outline) Descendants() iter.Seq2[Path, *Outline] {
+	return o.Traverse(DepthFirst).All()
+}
+
+// DescendantsBreadthFirst returns an iterator over the subordinated Outlines of
+// the Outline and their Path relative to the Outline, in breadth-first order.
+func (o *Outline) DescendantsBreadthFirst() iter.Seq2[Path, *Outline] {
+	return o.Traverse(BreadthFirst).All()
+}
+
+// Traverse returns a Traversal of the subordinated Outlines of the Outline in
+// the given order, with Paths relative to the Outline.
+func (o *Outline) Traverse(order TraversalOrder) *Traversal {
+	return &Traversal{
+		walk:  o.Walk,
+		order: order,
+	}
+}
+
+// A Traversal iterates over Outlines in a given order, and allows skipping the
+// subordinated Outlines of the current Outline:
+//
+//	traversal := document.Traverse(opml.DepthFirst)
+//
+//	for path, outline := range traversal.All() {
+//		if outline.IsComment {
+//			traversal.SkipOutlines()
+//			continue
+//		}
+//		...
+//	}
+//
+// Breaking out of the loop skips all remaining Outlines.
+type Traversal struct {
+	walk  func(order TraversalOrder, fn WalkFunc) error
+	order TraversalOrder
+	skip  bool
+}
+
+// All returns an iterator over the Outlines of the Traversal and their Path.
+func (t *Traversal) All() iter.Seq2[Path, *Outline] {
+	return func(yield func(Path, *Outline) bool) {
+		_ = t.walk(t.order, func(path Path, outline *Outline) error {
+			t.skip = false
+
+			if !yield(path, outline) {
+				return SkipAll
+			}
+
+			if t.skip {
+				t.skip = false
+				return SkipOutlines
+			}
+
+			return nil
+		})
+	}
+}
+
+// SkipOutlines skips the subordinated Outlines of the Outline that was last
+// yielded by All.
+func (t *Traversal) SkipOutlines() {
+	t.skip = true
+}
+
+// walkOutlines visits outlines and their subordinated Outlines.
+func walkOutlines(outlines []Outline, order TraversalOrder, fn WalkFunc) error {
+	var err error
+
+	switch order {
+	case BreadthFirst:
+		err = walkOutlinesBreadthFirst(outlines, fn)
+	default:
+		err = walkOutlinesDepthFirst(nil, outlines, fn)
+	}
+
+	if err == SkipAll {
+		return nil
+	}
+
+	return err
+}
+
+// walkOutlinesDepthFirst visits outlines and their subordinated Outlines,
+// prefixing their Path with parent.
+//
+// The parent Path is a buffer that is reused while descending the tree; fn is
+// given a copy of each Path.
+func walkOutlinesDepthFirst(parent Path, outlines []Outline, fn WalkFunc) error {
+	for index := range outlines {
+		path := append(parent, index)
+		outline := &outlines[index]
+
+		err := fn(path.clone(), outline)
+		if err == SkipOutlines {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := walkOutlinesDepthFirst(path, outline.Outlines, fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func walkOutlinesBreadthFirst(outlines []Outline, fn WalkFunc) error {
+	// Queued Outlines reference the Path of their parent, which is not
+	// given to fn, so that each Path is allocated once
+	type node struct {
+		parent  Path
+		index   int
+		outline *Outline
+	}
+
+	var queue []node
+
+	for index := range outlines {
+		queue = append(queue, node{
+			index:   index,
+			outline: &outlines[index],
+		})
+	}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		err := fn(current.parent.child(current.index), current.outline)
+		if err == SkipOutlines {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if len(current.outline.Outlines) == 0 {
+			continue
+		}
+
+		path := current.parent.child(current.index)
+
+		for index := range current.outline.Outlines {
+			queue = append(queue, node{
+				parent:  path,
+				index:   index,
+				outline: &current.outline.Outlines[index],
+			})
+		}
+	}
+
+	return nil
+}
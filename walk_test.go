@@ -0,0 +1,339 @@
+// Copyright (c) VirtualTam
+// SPDX-License-Identifier: MIT
+
+package opml
+
+import (
+	"errors"
+	"iter"
+	"slices"
+	"testing"
+)
+
+// walkDocument is a Document with the following lines:
+//
+//	1 Programming (expanded)
+//	2   Elixir (expanded)
+//	3     Elixir Lang
+//	4   Go (scrolled to)
+//	5 News (expanded)
+//	6   Lobsters
+//	7 Unsorted
+//
+// Tests modifying it work on a copy returned by cloneDocument.
+var walkDocument = Document{
+	Version: Version2,
+	Head: Head{
+		ExpansionState:  []int{1, 2, 5},
+		VertScrollState: 4,
+	},
+	Body: Body{
+		Outlines: []Outline{
+			{
+				Text: "Programming",
+				Outlines: []Outline{
+					{
+						Text: "Elixir",
+						Outlines: []Outline{
+							{Text: "Elixir Lang"},
+						},
+					},
+					{Text: "Go"},
+				},
+			},
+			{
+				Text: "News",
+				Outlines: []Outline{
+					{Text: "Lobsters"},
+				},
+			},
+			{Text: "Unsorted"},
+		},
+	},
+}
+
+func collectOutlines(seq iter.Seq2[Path, *Outline]) []string {
+	var got []string
+
+	for path, outline := range seq {
+		got = append(got, path.String()+" "+outline.Text)
+	}
+
+	return got
+}
+
+func TestDocumentAll(t *testing.T) {
+	cases := []struct {
+		tname string
+		seq   iter.Seq2[Path, *Outline]
+		want  []string
+	}{
+		{
+			tname: "depth first",
+			seq:   walkDocument.All(),
+			want: []string{
+				"0 Programming",
+				"0.0 Elixir",
+				"0.0.0 Elixir Lang",
+				"0.1 Go",
+				"1 News",
+				"1.0 Lobsters",
+				"2 Unsorted",
+			},
+		},
+		{
+			tname: "breadth first",
+			seq:   walkDocument.AllBreadthFirst(),
+			want: []string{
+				"0 Programming",
+				"1 News",
+				"2 Unsorted",
+				"0.0 Elixir",
+				"0.1 Go",
+				"1.0 Lobsters",
+				"0.0.0 Elixir Lang",
+			},
+		},
+		{
+			tname: "descendants",
+			seq:   walkDocument.Body.Outlines[0].Descendants(),
+			want: []string{
+				"0 Elixir",
+				"0.0 Elixir Lang",
+				"1 Go",
+			},
+		},
+		{
+			tname: "descendants breadth first",
+			seq:   walkDocument.Body.Outlines[0].DescendantsBreadthFirst(),
+			want: []string{
+				"0 Elixir",
+				"1 Go",
+				"0.0 Elixir Lang",
+			},
+		},
+		{
+			tname: "empty",
+			seq:   (&Document{}).All(),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.tname, func(t *testing.T) {
+			got := collectOutlines(tc.seq)
+
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestDocumentAllBreak(t *testing.T) {
+	var got []string
+
+	for path, outline := range walkDocument.All() {
+		if path.String() == "0.1" {
+			break
+		}
+
+		got = append(got, outline.Text)
+	}
+
+	want := []string{"Programming", "Elixir", "Elixir Lang"}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestDocumentAllRetainPaths(t *testing.T) {
+	cases := []struct {
+		tname string
+		seq   iter.Seq2[Path, *Outline]
+	}{
+		{
+			tname: "depth first",
+			seq:   walkDocument.All(),
+		},
+		{
+			tname: "breadth first",
+			seq:   walkDocument.AllBreadthFirst(),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.tname, func(t *testing.T) {
+			var (
+				paths []Path
+				want  []string
+			)
+
+			for path := range tc.seq {
+				paths = append(paths, path)
+				want = append(want, path.String())
+			}
+
+			var got []string
+			for _, path := range paths {
+				got = append(got, path.String())
+			}
+
+			if !slices.Equal(got, want) {
+				t.Errorf("want %q, got %q", want, got)
+			}
+		})
+	}
+}
+
+func TestTraversalSkipOutlines(t *testing.T) {
+	cases := []struct {
+		tname     string
+		traversal *Traversal
+		skip      string
+		want      []string
+	}{
+		{
+			tname:     "depth first",
+			traversal: walkDocument.Traverse(DepthFirst),
+			skip:      "Programming",
+			want:      []string{"0 Programming", "1 News", "1.0 Lobsters", "2 Unsorted"},
+		},
+		{
+			tname:     "breadth first",
+			traversal: walkDocument.Traverse(BreadthFirst),
+			skip:      "Elixir",
+			want:      []string{"0 Programming", "1 News", "2 Unsorted", "0.0 Elixir", "0.1 Go", "1.0 Lobsters"},
+		},
+		{
+			tname:     "descendants",
+			traversal: walkDocument.Body.Outlines[0].Traverse(DepthFirst),
+			skip:      "Elixir",
+			want:      []string{"0 Elixir", "1 Go"},
+		},
+		{
+			tname:     "skip leaf",
+			traversal: walkDocument.Traverse(DepthFirst),
+			skip:      "Elixir Lang",
+			want:      []string{"0 Programming", "0.0 Elixir", "0.0.0 Elixir Lang", "0.1 Go", "1 News", "1.0 Lobsters", "2 Unsorted"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.tname, func(t *testing.T) {
+			var got []string
+
+			for path, outline := range tc.traversal.All() {
+				got = append(got, path.String()+" "+outline.Text)
+
+				if outline.Text == tc.skip {
+					tc.traversal.SkipOutlines()
+				}
+			}
+
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestDocumentAllModify(t *testing.T) {
+	document := Document{
+		Body: Body{
+			Outlines: []Outline{
+				{
+					Text: "News",
+					Outlines: []Outline{
+						{Text: "Lobsters"},
+					},
+				},
+			},
+		},
+	}
+
+	for _, outline := range document.All() {
+		outline.Title = outline.Text
+	}
+
+	if got := document.Body.Outlines[0].Outlines[0].Title; got != "Lobsters" {
+		t.Errorf("want Title %q, got %q", "Lobsters", got)
+	}
+}
+
+func TestDocumentWalk(t *testing.T) {
+	errWalk := errors.New("walk error")
+
+	cases := []struct {
+		tname   string
+		order   TraversalOrder
+		fn      func(path Path, outline *Outline) error
+		want    []string
+		wantErr error
+	}{
+		{
+			tname: "skip outlines, depth first",
+			order: DepthFirst,
+			fn: func(path Path, outline *Outline) error {
+				if outline.Text == "Programming" {
+					return SkipOutlines
+				}
+				return nil
+			},
+			want: []string{"0 Programming", "1 News", "1.0 Lobsters", "2 Unsorted"},
+		},
+		{
+			tname: "skip outlines, breadth first",
+			order: BreadthFirst,
+			fn: func(path Path, outline *Outline) error {
+				if outline.Text == "Elixir" {
+					return SkipOutlines
+				}
+				return nil
+			},
+			want: []string{"0 Programming", "1 News", "2 Unsorted", "0.0 Elixir", "0.1 Go", "1.0 Lobsters"},
+		},
+		{
+			tname: "skip all",
+			order: DepthFirst,
+			fn: func(path Path, outline *Outline) error {
+				if outline.Text == "News" {
+					return SkipAll
+				}
+				return nil
+			},
+			want: []string{"0 Programming", "0.0 Elixir", "0.0.0 Elixir Lang", "0.1 Go", "1 News"},
+		},
+		{
+			tname: "error",
+			order: BreadthFirst,
+			fn: func(path Path, outline *Outline) error {
+				if outline.Text == "Go" {
+					return errWalk
+				}
+				return nil
+			},
+			want:    []string{"0 Programming", "1 News", "2 Unsorted", "0.0 Elixir", "0.1 Go"},
+			wantErr: errWalk,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.tname, func(t *testing.T) {
+			var got []string
+
+			err := walkDocument.Walk(tc.order, func(path Path, outline *Outline) error {
+				got = append(got, path.String()+" "+outline.Text)
+				return tc.fn(path, outline)
+			})
+
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("want error %q, got %q", tc.wantErr, err)
+			}
+
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}